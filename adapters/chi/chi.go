@@ -0,0 +1,109 @@
+// Package inertiachi adapts inertigo to the chi router.
+//
+// chi handlers are plain net/http handlers, so Inertia.Middleware and
+// Inertia.Render work as they are. This package adds handlers that return
+// errors and middleware for shared props:
+//
+//	a := inertiachi.New(i)
+//
+//	r := chi.NewRouter()
+//	r.Use(a.Middleware)
+//	r.Use(a.ShareFunc(func(r *http.Request) (inertia.Props, error) {
+//	    return inertia.Props{"user": inertia.Value(currentUser(r))}, nil
+//	}))
+//
+//	r.Get("/users/{id}", a.Handler(func(w http.ResponseWriter, r *http.Request) error {
+//	    user, err := users.Find(r.Context(), chi.URLParam(r, "id"))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return a.Render(w, r, "users/Show", inertia.Props{"user": inertia.Value(user)})
+//	}))
+package inertiachi
+
+import (
+	"log/slog"
+	"net/http"
+
+	inertia "github.com/joetifa2003/inertigo"
+)
+
+// HandlerFunc is an http.HandlerFunc that returns an error.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ErrorHandler writes the response for an error returned by a HandlerFunc.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// Adapter binds an Inertia instance to chi. The Inertia methods, such as
+// Middleware, Render and Redirect, can be called on it directly.
+type Adapter struct {
+	*inertia.Inertia
+
+	errorHandler ErrorHandler
+}
+
+// Option configures an Adapter.
+type Option func(*Adapter)
+
+// WithErrorHandler sets how errors returned by handlers are written. The
+// default is Inertia.HandleError.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(a *Adapter) {
+		a.errorHandler = handler
+	}
+}
+
+// New returns an Adapter for i.
+func New(i *inertia.Inertia, options ...Option) *Adapter {
+	a := &Adapter{Inertia: i}
+	a.errorHandler = i.HandleError
+
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+// Handler converts fn to an http.HandlerFunc, passing the errors it returns
+// to Error.
+func (a *Adapter) Handler(fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rw := inertia.NewTrackingWriter(w)
+		if err := fn(rw, r); err != nil {
+			a.Error(rw, r, err)
+		}
+	}
+}
+
+// Error writes the response for err with the adapter's ErrorHandler. The
+// response is left alone if w reports it has already been written, as the
+// inertia.TrackingWriter Handler passes does.
+func (a *Adapter) Error(w http.ResponseWriter, r *http.Request, err error) {
+	if tracked, ok := w.(interface{ Written() bool }); ok && tracked.Written() {
+		a.Logger().LogAttrs(r.Context(), slog.LevelError, "inertia handler failed after response was written",
+			slog.String("path", r.URL.Path),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	a.errorHandler(w, r, err)
+}
+
+// ShareFunc returns middleware sharing the props fn returns with every page
+// rendered for the request. An error from fn is passed to the adapter's
+// ErrorHandler and stops the request.
+func (a *Adapter) ShareFunc(fn func(r *http.Request) (inertia.Props, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			props, err := fn(r)
+			if err != nil {
+				a.errorHandler(w, r, err)
+				return
+			}
+
+			inertia.ShareMultiple(r, props)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package inertiachi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	inertiachi "github.com/joetifa2003/inertigo/adapters/chi"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+func newAdapter(t *testing.T, options ...inertiachi.Option) *inertiachi.Adapter {
+	t.Helper()
+
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	return inertiachi.New(i, options...)
+}
+
+func decodePage(t *testing.T, w *httptest.ResponseRecorder) inertia.PageObject {
+	t.Helper()
+
+	var page inertia.PageObject
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+	return page
+}
+
+func TestAdapter(t *testing.T) {
+	a := newAdapter(t)
+
+	r := chi.NewRouter()
+	r.Use(a.Middleware)
+	r.Use(a.ShareFunc(func(r *http.Request) (inertia.Props, error) {
+		return inertia.Props{"appName": inertia.Value("Inertigo")}, nil
+	}))
+
+	r.Get("/users/{id}", a.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return a.Render(w, r, "users/Show", inertia.Props{
+			"id": inertia.Value(chi.URLParam(r, "id")),
+		})
+	}))
+	r.Get("/fail", a.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("boom")
+	}))
+	r.Get("/partial", a.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.Write([]byte("partial"))
+		return errors.New("boom")
+	}))
+
+	t.Run("renders with shared props", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users/42", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		page := decodePage(t, w)
+		assert.Equal(t, "users/Show", page.Component)
+		assert.Equal(t, "42", page.Props["id"])
+		assert.Equal(t, "Inertigo", page.Props["appName"])
+	})
+
+	t.Run("maps errors to 500", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/fail", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("renders the error page for Inertia requests", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/fail", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		page := decodePage(t, w)
+		assert.Equal(t, "Error", page.Component)
+		assert.EqualValues(t, http.StatusInternalServerError, page.Props["status"])
+		assert.Equal(t, "Inertigo", page.Props["appName"])
+	})
+
+	t.Run("leaves a written response alone", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/partial", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "partial", w.Body.String())
+	})
+}
+
+func TestAdapter_WithErrorHandler(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	a := newAdapter(t, inertiachi.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		if errors.Is(err, errNotFound) {
+			http.Error(w, "missing", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "oops", http.StatusInternalServerError)
+	}))
+
+	r := chi.NewRouter()
+	r.Use(a.Middleware)
+	r.Use(a.ShareFunc(func(r *http.Request) (inertia.Props, error) {
+		if r.URL.Query().Has("deny") {
+			return nil, errNotFound
+		}
+		return nil, nil
+	}))
+	r.Get("/", a.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return errNotFound
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "missing\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/?deny", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,190 @@
+// Package inertiaecho adapts inertigo to the Echo framework.
+//
+//	a := inertiaecho.New(i)
+//
+//	e := echo.New()
+//	e.Use(a.Middleware)
+//	e.Use(a.ShareFunc(func(c echo.Context) (inertia.Props, error) {
+//	    return inertia.Props{"user": inertia.Value(currentUser(c))}, nil
+//	}))
+//
+//	e.GET("/users/:id", func(c echo.Context) error {
+//	    user, err := users.Find(c.Request().Context(), c.Param("id"))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return a.Render(c, "users/Show", inertia.Props{"user": inertia.Value(user)})
+//	})
+package inertiaecho
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	inertia "github.com/joetifa2003/inertigo"
+)
+
+// ErrorHandler writes the response for an error returned by a handler.
+type ErrorHandler func(c echo.Context, err error)
+
+// Adapter binds an Inertia instance to Echo.
+type Adapter struct {
+	i            *inertia.Inertia
+	errorHandler ErrorHandler
+}
+
+// Option configures an Adapter.
+type Option func(*Adapter)
+
+// WithErrorHandler sets how errors returned by handlers are written. The
+// default is Inertia.HandleError, using the status of an *echo.HTTPError.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(a *Adapter) {
+		a.errorHandler = handler
+	}
+}
+
+// New returns an Adapter for i.
+func New(i *inertia.Inertia, options ...Option) *Adapter {
+	a := &Adapter{i: i}
+	a.errorHandler = a.defaultErrorHandler
+
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+// Inertia returns the adapted Inertia instance.
+func (a *Adapter) Inertia() *inertia.Inertia {
+	return a.i
+}
+
+// Middleware runs Inertia.Middleware for Echo. Errors returned further down
+// the chain are written with the adapter's ErrorHandler while the request's
+// shared props are still available, so they are not passed on to Echo's
+// HTTPErrorHandler.
+func (a *Adapter) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		a.i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.SetRequest(r)
+			if err := next(c); err != nil {
+				a.Error(c, err)
+			}
+		})).ServeHTTP(responseWriter{c.Response()}, c.Request())
+		return nil
+	}
+}
+
+// responseWriter reports whether an Echo response has been written, for
+// Inertia's panic recovery, since handlers write to it directly.
+type responseWriter struct {
+	*echo.Response
+}
+
+func (w responseWriter) Written() bool {
+	return w.Committed
+}
+
+// ShareFunc returns middleware sharing the props fn returns with every page
+// rendered for the request. It must run after Middleware.
+func (a *Adapter) ShareFunc(fn func(c echo.Context) (inertia.Props, error)) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			props, err := fn(c)
+			if err != nil {
+				return err
+			}
+
+			ShareMultiple(c, props)
+			return next(c)
+		}
+	}
+}
+
+// Error writes the response for err with the adapter's ErrorHandler. It does
+// nothing if the response has already been written.
+func (a *Adapter) Error(c echo.Context, err error) {
+	if c.Response().Committed {
+		a.i.Logger().LogAttrs(c.Request().Context(), slog.LevelError, "inertia handler failed after response was written",
+			slog.String("path", c.Request().URL.Path),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	a.errorHandler(c, err)
+}
+
+// Render renders component, as with Inertia.Render.
+func (a *Adapter) Render(c echo.Context, component string, props inertia.Props, options ...inertia.RenderOption) error {
+	return a.i.Render(c.Response(), c.Request(), component, props, options...)
+}
+
+// RenderTyped renders component with props from a struct, as with
+// inertia.RenderTyped.
+func RenderTyped[P any](a *Adapter, c echo.Context, component string, props P, options ...inertia.RenderOption) error {
+	return inertia.RenderTyped(a.i, c.Response(), c.Request(), component, props, options...)
+}
+
+// RenderErrors responds with validation errors, as with Inertia.RenderErrors.
+func (a *Adapter) RenderErrors(c echo.Context, errors map[string]any) error {
+	return a.i.RenderErrors(c.Response(), c.Request(), errors)
+}
+
+// Share adds a shared prop for the current request.
+func Share(c echo.Context, key string, prop inertia.Prop) {
+	inertia.Share(c.Request(), key, prop)
+}
+
+// ShareMultiple adds shared props for the current request.
+func ShareMultiple(c echo.Context, props inertia.Props) {
+	inertia.ShareMultiple(c.Request(), props)
+}
+
+// Flash stores data for the next request, as with Inertia.Flash.
+func (a *Adapter) Flash(c echo.Context, key string, value any) error {
+	return a.i.Flash(c.Response(), c.Request(), key, value)
+}
+
+// Reflash keeps the current flash data for the next request, as with
+// Inertia.Reflash.
+func (a *Adapter) Reflash(c echo.Context) error {
+	return a.i.Reflash(c.Response(), c.Request())
+}
+
+// Keep keeps the given flash keys for the next request, as with
+// Inertia.Keep.
+func (a *Adapter) Keep(c echo.Context, keys ...string) error {
+	return a.i.Keep(c.Response(), c.Request(), keys...)
+}
+
+// Redirect redirects to url, as with Inertia.Redirect.
+func (a *Adapter) Redirect(c echo.Context, url string) error {
+	a.i.Redirect(c.Response(), c.Request(), url)
+	return nil
+}
+
+// RedirectBack redirects to the previous page, as with Inertia.RedirectBack.
+func (a *Adapter) RedirectBack(c echo.Context) error {
+	a.i.RedirectBack(c.Response(), c.Request())
+	return nil
+}
+
+// Location redirects to an external URL, as with Inertia.Location.
+func (a *Adapter) Location(c echo.Context, url string) error {
+	a.i.Location(c.Response(), c.Request(), url)
+	return nil
+}
+
+func (a *Adapter) defaultErrorHandler(c echo.Context, err error) {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		err = inertia.NewHTTPError(he.Code, err)
+	}
+
+	a.i.HandleError(c.Response(), c.Request(), err)
+}
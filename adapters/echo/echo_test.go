@@ -0,0 +1,173 @@
+package inertiaecho_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	inertiaecho "github.com/joetifa2003/inertigo/adapters/echo"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+func newEcho(t *testing.T, options ...inertiaecho.Option) (*echo.Echo, *inertiaecho.Adapter) {
+	t.Helper()
+
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	a := inertiaecho.New(i, options...)
+
+	e := echo.New()
+	e.Use(a.Middleware)
+	e.Use(a.ShareFunc(func(c echo.Context) (inertia.Props, error) {
+		if c.QueryParam("deny") != "" {
+			return nil, echo.NewHTTPError(http.StatusForbidden)
+		}
+		return inertia.Props{"appName": inertia.Value("Inertigo")}, nil
+	}))
+
+	return e, a
+}
+
+func decodePage(t *testing.T, rec *httptest.ResponseRecorder) inertia.PageObject {
+	t.Helper()
+
+	var page inertia.PageObject
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	return page
+}
+
+type showPage struct {
+	ID string `inertia:"id"`
+}
+
+func TestAdapter(t *testing.T) {
+	e, a := newEcho(t)
+
+	e.GET("/users/:id", func(c echo.Context) error {
+		inertiaecho.Share(c, "section", inertia.Value("users"))
+		return a.Render(c, "users/Show", inertia.Props{"id": inertia.Value(c.Param("id"))})
+	})
+	e.GET("/typed/:id", func(c echo.Context) error {
+		return inertiaecho.RenderTyped(a, c, "users/Show", showPage{ID: c.Param("id")})
+	})
+	e.POST("/users", func(c echo.Context) error {
+		if err := a.Flash(c, "message", "created"); err != nil {
+			return err
+		}
+		return a.Redirect(c, "/users/1")
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return errors.New("boom")
+	})
+
+	t.Run("renders with shared props", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users/42", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		page := decodePage(t, rec)
+		assert.Equal(t, "users/Show", page.Component)
+		assert.Equal(t, "42", page.Props["id"])
+		assert.Equal(t, "Inertigo", page.Props["appName"])
+		assert.Equal(t, "users", page.Props["section"])
+	})
+
+	t.Run("renders typed props", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/typed/7", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "7", decodePage(t, rec).Props["id"])
+	})
+
+	t.Run("flashes and redirects", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/users", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "/users/1", rec.Header().Get("Location"))
+		assert.NotEmpty(t, rec.Result().Cookies())
+	})
+
+	t.Run("maps errors", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest("GET", "/fail", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest("GET", "/users/1?deny=1", nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest("GET", "/missing", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestAdapter_WithErrorHandler(t *testing.T) {
+	var handled error
+	e, _ := newEcho(t, inertiaecho.WithErrorHandler(func(c echo.Context, err error) {
+		handled = err
+		_ = c.String(http.StatusTeapot, "custom")
+	}))
+
+	e.GET("/", func(c echo.Context) error {
+		return errors.New("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "custom", rec.Body.String())
+	assert.EqualError(t, handled, "boom")
+}
+
+func TestAdapter_Recovery(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(false))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler, inertia.WithRecovery(true))
+	require.NoError(t, err)
+
+	a := inertiaecho.New(i)
+
+	e := echo.New()
+	e.Use(a.Middleware)
+	e.GET("/panic", func(c echo.Context) error {
+		panic("boom")
+	})
+	e.GET("/partial", func(c echo.Context) error {
+		_ = c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set(inertia.XInertia, "true")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "Error", decodePage(t, rec).Component)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/partial", nil))
+	})
+}
@@ -0,0 +1,314 @@
+// Package inertiafiber adapts inertigo to the Fiber framework.
+//
+// Fiber is built on fasthttp rather than net/http, so the adapter converts
+// each request once in Middleware and renders through a response writer
+// backed by the Fiber context.
+//
+//	a := inertiafiber.New(i)
+//
+//	app := fiber.New()
+//	app.Use(a.Middleware)
+//	app.Use(a.ShareFunc(func(c *fiber.Ctx) (inertia.Props, error) {
+//	    return inertia.Props{"user": inertia.Value(currentUser(c))}, nil
+//	}))
+//
+//	app.Get("/users/:id", func(c *fiber.Ctx) error {
+//	    user, err := users.Find(c.UserContext(), c.Params("id"))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return a.Render(c, "users/Show", inertia.Props{"user": inertia.Value(user)})
+//	})
+package inertiafiber
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+
+	inertia "github.com/joetifa2003/inertigo"
+)
+
+// ErrMiddlewareMissing is returned by Render and the other helpers when
+// Adapter.Middleware has not run for the request.
+var ErrMiddlewareMissing = errors.New("inertiafiber: Middleware has not run for this request")
+
+// ErrorHandler writes the response for an error returned by a handler.
+type ErrorHandler func(c *fiber.Ctx, err error) error
+
+// Adapter binds an Inertia instance to Fiber.
+type Adapter struct {
+	i            *inertia.Inertia
+	errorHandler ErrorHandler
+}
+
+// Option configures an Adapter.
+type Option func(*Adapter)
+
+// WithErrorHandler sets how errors returned by handlers are written. The
+// default is Inertia.HandleError, using the status of a *fiber.Error.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(a *Adapter) {
+		a.errorHandler = handler
+	}
+}
+
+// New returns an Adapter for i.
+func New(i *inertia.Inertia, options ...Option) *Adapter {
+	a := &Adapter{i: i}
+	a.errorHandler = a.defaultErrorHandler
+
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+// Inertia returns the adapted Inertia instance.
+func (a *Adapter) Inertia() *inertia.Inertia {
+	return a.i
+}
+
+// localsKey is the Fiber locals key of a request's state.
+const localsKey = "inertigo"
+
+// state is the net/http view of a Fiber request, shared by the helpers.
+type state struct {
+	w *responseWriter
+	r *http.Request
+}
+
+func getState(c *fiber.Ctx) (*state, error) {
+	st, ok := c.Locals(localsKey).(*state)
+	if !ok || st.r == nil {
+		return nil, ErrMiddlewareMissing
+	}
+	return st, nil
+}
+
+// Middleware runs Inertia.Middleware for Fiber. Errors returned further down
+// the chain are written with the adapter's ErrorHandler while the request's
+// shared props are still available, so they are not passed on to the app's
+// ErrorHandler.
+func (a *Adapter) Middleware(c *fiber.Ctx) error {
+	r, err := adaptor.ConvertRequest(c, false)
+	if err != nil {
+		return err
+	}
+	r = r.WithContext(c.UserContext())
+
+	st := &state{w: &responseWriter{c: c, header: http.Header{}}}
+	c.Locals(localsKey, st)
+	defer c.Locals(localsKey, nil)
+
+	var handlerErr error
+	a.i.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		st.r = r
+		if err := c.Next(); err != nil {
+			handlerErr = a.Error(c, err)
+		}
+	})).ServeHTTP(st.w, r)
+
+	// Headers set without writing through w, e.g. the session cookie of a
+	// flash followed by c.Redirect, still need to reach the response.
+	st.w.copyHeaders()
+
+	return handlerErr
+}
+
+// ShareFunc returns middleware sharing the props fn returns with every page
+// rendered for the request. It must run after Middleware.
+func (a *Adapter) ShareFunc(fn func(c *fiber.Ctx) (inertia.Props, error)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		props, err := fn(c)
+		if err != nil {
+			return err
+		}
+
+		ShareMultiple(c, props)
+		return c.Next()
+	}
+}
+
+// Error writes the response for err with the adapter's ErrorHandler. The
+// response is left alone if it has already been written, by Inertia or by
+// the handler through c.
+func (a *Adapter) Error(c *fiber.Ctx, err error) error {
+	if st, _ := c.Locals(localsKey).(*state); st != nil && st.w.Written() {
+		a.i.Logger().LogAttrs(c.UserContext(), slog.LevelError, "inertia handler failed after response was written",
+			slog.String("path", c.Path()),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	return a.errorHandler(c, err)
+}
+
+// Render renders component, as with Inertia.Render.
+func (a *Adapter) Render(c *fiber.Ctx, component string, props inertia.Props, options ...inertia.RenderOption) error {
+	st, err := getState(c)
+	if err != nil {
+		return err
+	}
+	return a.i.Render(st.w, st.r, component, props, options...)
+}
+
+// RenderTyped renders component with props from a struct, as with
+// inertia.RenderTyped.
+func RenderTyped[P any](a *Adapter, c *fiber.Ctx, component string, props P, options ...inertia.RenderOption) error {
+	st, err := getState(c)
+	if err != nil {
+		return err
+	}
+	return inertia.RenderTyped(a.i, st.w, st.r, component, props, options...)
+}
+
+// RenderErrors responds with validation errors, as with Inertia.RenderErrors.
+func (a *Adapter) RenderErrors(c *fiber.Ctx, errors map[string]any) error {
+	st, err := getState(c)
+	if err != nil {
+		return err
+	}
+	return a.i.RenderErrors(st.w, st.r, errors)
+}
+
+// Share adds a shared prop for the current request.
+func Share(c *fiber.Ctx, key string, prop inertia.Prop) {
+	if st, err := getState(c); err == nil {
+		inertia.Share(st.r, key, prop)
+	}
+}
+
+// ShareMultiple adds shared props for the current request.
+func ShareMultiple(c *fiber.Ctx, props inertia.Props) {
+	if st, err := getState(c); err == nil {
+		inertia.ShareMultiple(st.r, props)
+	}
+}
+
+// Flash stores data for the next request, as with Inertia.Flash.
+func (a *Adapter) Flash(c *fiber.Ctx, key string, value any) error {
+	st, err := getState(c)
+	if err != nil {
+		return err
+	}
+	return a.i.Flash(st.w, st.r, key, value)
+}
+
+// Reflash keeps the current flash data for the next request, as with
+// Inertia.Reflash.
+func (a *Adapter) Reflash(c *fiber.Ctx) error {
+	st, err := getState(c)
+	if err != nil {
+		return err
+	}
+	return a.i.Reflash(st.w, st.r)
+}
+
+// Keep keeps the given flash keys for the next request, as with
+// Inertia.Keep.
+func (a *Adapter) Keep(c *fiber.Ctx, keys ...string) error {
+	st, err := getState(c)
+	if err != nil {
+		return err
+	}
+	return a.i.Keep(st.w, st.r, keys...)
+}
+
+// Redirect redirects to url, as with Inertia.Redirect.
+func (a *Adapter) Redirect(c *fiber.Ctx, url string) error {
+	st, err := getState(c)
+	if err != nil {
+		return err
+	}
+	a.i.Redirect(st.w, st.r, url)
+	return nil
+}
+
+// RedirectBack redirects to the previous page, as with Inertia.RedirectBack.
+func (a *Adapter) RedirectBack(c *fiber.Ctx) error {
+	st, err := getState(c)
+	if err != nil {
+		return err
+	}
+	a.i.RedirectBack(st.w, st.r)
+	return nil
+}
+
+// Location redirects to an external URL, as with Inertia.Location.
+func (a *Adapter) Location(c *fiber.Ctx, url string) error {
+	st, err := getState(c)
+	if err != nil {
+		return err
+	}
+	a.i.Location(st.w, st.r, url)
+	return nil
+}
+
+func (a *Adapter) defaultErrorHandler(c *fiber.Ctx, err error) error {
+	st, stErr := getState(c)
+	if stErr != nil {
+		return err
+	}
+
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		err = inertia.NewHTTPError(fe.Code, err)
+	}
+
+	a.i.HandleError(st.w, st.r, err)
+	return nil
+}
+
+// responseWriter is an http.ResponseWriter writing to a Fiber response.
+type responseWriter struct {
+	c           *fiber.Ctx
+	header      http.Header
+	wroteHeader bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	w.copyHeaders()
+	w.c.Status(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.c.Write(b)
+}
+
+// Written reports whether the response has started, for Inertia's panic
+// recovery. This includes a body written directly with c, e.g. with
+// c.SendString or c.JSON.
+func (w *responseWriter) Written() bool {
+	return w.wroteHeader || len(w.c.Response().Body()) > 0
+}
+
+// copyHeaders moves the headers set so far to the Fiber response.
+func (w *responseWriter) copyHeaders() {
+	for key, values := range w.header {
+		// Deleting Set-Cookie would drop cookies copied earlier.
+		if key != "Set-Cookie" {
+			w.c.Response().Header.Del(key)
+		}
+		for _, value := range values {
+			w.c.Response().Header.Add(key, value)
+		}
+	}
+	clear(w.header)
+}
@@ -0,0 +1,224 @@
+package inertiafiber_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	inertiafiber "github.com/joetifa2003/inertigo/adapters/fiber"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+func newApp(t *testing.T, options ...inertiafiber.Option) (*fiber.App, *inertiafiber.Adapter) {
+	t.Helper()
+
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler,
+		inertia.WithVersion("v2"),
+		inertia.WithRootHtmlPathFS(fstest.MapFS{
+			"index.html": &fstest.MapFile{
+				Data: []byte(`<html><head>{{ .InertiaHead }}</head><body>{{ .InertiaBody }}</body></html>`),
+			},
+		}, "index.html"),
+	)
+	require.NoError(t, err)
+
+	a := inertiafiber.New(i, options...)
+
+	app := fiber.New()
+	app.Use(a.Middleware)
+	app.Use(a.ShareFunc(func(c *fiber.Ctx) (inertia.Props, error) {
+		if c.Query("deny") != "" {
+			return nil, fiber.ErrForbidden
+		}
+		return inertia.Props{"appName": inertia.Value("Inertigo")}, nil
+	}))
+
+	return app, a
+}
+
+func decodePage(t *testing.T, resp *http.Response) inertia.PageObject {
+	t.Helper()
+
+	var page inertia.PageObject
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	return page
+}
+
+type showPage struct {
+	ID string `inertia:"id"`
+}
+
+func TestAdapter(t *testing.T) {
+	app, a := newApp(t)
+
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		inertiafiber.Share(c, "section", inertia.Value("users"))
+		return a.Render(c, "users/Show", inertia.Props{"id": inertia.Value(c.Params("id"))})
+	})
+	app.Get("/typed/:id", func(c *fiber.Ctx) error {
+		return inertiafiber.RenderTyped(a, c, "users/Show", showPage{ID: c.Params("id")})
+	})
+	app.Post("/users", func(c *fiber.Ctx) error {
+		if err := a.Flash(c, "message", "created"); err != nil {
+			return err
+		}
+		return a.Redirect(c, "/users/1")
+	})
+	app.Get("/flashed", func(c *fiber.Ctx) error {
+		if err := a.Flash(c, "message", "hi"); err != nil {
+			return err
+		}
+		return c.Redirect("/")
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return errors.New("boom")
+	})
+	app.Get("/partial", func(c *fiber.Ctx) error {
+		if err := c.SendString("partial"); err != nil {
+			return err
+		}
+		return errors.New("boom")
+	})
+
+	t.Run("renders with shared props", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users/42?tab=posts", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "true", resp.Header.Get(inertia.XInertia))
+		page := decodePage(t, resp)
+		assert.Equal(t, "users/Show", page.Component)
+		assert.Equal(t, "/users/42?tab=posts", page.URL)
+		assert.Equal(t, "42", page.Props["id"])
+		assert.Equal(t, "Inertigo", page.Props["appName"])
+		assert.Equal(t, "users", page.Props["section"])
+	})
+
+	t.Run("renders html on full load", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/users/42", nil))
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "users/Show")
+	})
+
+	t.Run("renders typed props", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/typed/7", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "7", decodePage(t, resp).Props["id"])
+	})
+
+	t.Run("flashes and redirects", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("POST", "/users", nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusFound, resp.StatusCode)
+		assert.Equal(t, "/users/1", resp.Header.Get("Location"))
+		assert.NotEmpty(t, resp.Cookies())
+	})
+
+	t.Run("keeps session cookie with native redirect", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/flashed", nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusFound, resp.StatusCode)
+		assert.NotEmpty(t, resp.Cookies())
+	})
+
+	t.Run("maps errors", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/fail", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+		resp, err = app.Test(httptest.NewRequest("GET", "/users/1?deny=1", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, err = app.Test(httptest.NewRequest("GET", "/missing", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("leaves a response written with c alone", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/partial", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "partial", string(body))
+	})
+
+	t.Run("responds 409 on version mismatch", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		req.Header.Set(inertia.XInertiaVersion, "v1")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Equal(t, "/users/1", resp.Header.Get(inertia.XInertiaLocation))
+	})
+}
+
+func TestAdapter_WithoutMiddleware(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	a := inertiafiber.New(i)
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		err := a.Render(c, "Home", nil)
+		assert.ErrorIs(t, err, inertiafiber.ErrMiddlewareMissing)
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestAdapter_WithErrorHandler(t *testing.T) {
+	app, _ := newApp(t, inertiafiber.WithErrorHandler(func(c *fiber.Ctx, err error) error {
+		return c.Status(http.StatusTeapot).SendString("custom: " + err.Error())
+	}))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return errors.New("boom")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "custom: boom", string(body))
+}
@@ -0,0 +1,188 @@
+// Package inertiagin adapts inertigo to the Gin framework.
+//
+//	a := inertiagin.New(i)
+//
+//	r := gin.New()
+//	r.Use(a.Middleware)
+//	r.Use(a.ShareFunc(func(c *gin.Context) (inertia.Props, error) {
+//	    return inertia.Props{"user": inertia.Value(currentUser(c))}, nil
+//	}))
+//
+//	r.GET("/users/:id", a.Handler(func(c *gin.Context) error {
+//	    user, err := users.Find(c.Request.Context(), c.Param("id"))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return a.Render(c, "users/Show", inertia.Props{"user": inertia.Value(user)})
+//	}))
+package inertiagin
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	inertia "github.com/joetifa2003/inertigo"
+)
+
+// HandlerFunc is a gin.HandlerFunc that returns an error.
+type HandlerFunc func(c *gin.Context) error
+
+// ErrorHandler writes the response for an error returned by a HandlerFunc.
+type ErrorHandler func(c *gin.Context, err error)
+
+// Adapter binds an Inertia instance to Gin.
+type Adapter struct {
+	i            *inertia.Inertia
+	errorHandler ErrorHandler
+}
+
+// Option configures an Adapter.
+type Option func(*Adapter)
+
+// WithErrorHandler sets how errors returned by handlers are written. The
+// default is Inertia.HandleError.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(a *Adapter) {
+		a.errorHandler = handler
+	}
+}
+
+// New returns an Adapter for i.
+func New(i *inertia.Inertia, options ...Option) *Adapter {
+	a := &Adapter{i: i}
+	a.errorHandler = a.defaultErrorHandler
+
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+// Inertia returns the adapted Inertia instance.
+func (a *Adapter) Inertia() *inertia.Inertia {
+	return a.i
+}
+
+// Middleware runs Inertia.Middleware for Gin. The rest of the chain is
+// aborted if Inertia.Middleware responds itself, e.g. with 409 Conflict on
+// an asset version mismatch.
+func (a *Adapter) Middleware(c *gin.Context) {
+	called := false
+	a.i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		c.Request = r
+		c.Next()
+	})).ServeHTTP(c.Writer, c.Request)
+
+	if !called {
+		c.Abort()
+	}
+}
+
+// Handler converts fn to a gin.HandlerFunc, passing the errors it returns
+// to Error.
+func (a *Adapter) Handler(fn HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := fn(c); err != nil {
+			a.Error(c, err)
+		}
+	}
+}
+
+// ShareFunc returns middleware sharing the props fn returns with every page
+// rendered for the request. It must run after Middleware. An error from fn
+// is passed to Error and aborts the chain.
+func (a *Adapter) ShareFunc(fn func(c *gin.Context) (inertia.Props, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		props, err := fn(c)
+		if err != nil {
+			a.Error(c, err)
+			c.Abort()
+			return
+		}
+
+		ShareMultiple(c, props)
+		c.Next()
+	}
+}
+
+// Error records err on the context and writes the response for it with the
+// adapter's ErrorHandler. The response is left alone if it has already been
+// written.
+func (a *Adapter) Error(c *gin.Context, err error) {
+	_ = c.Error(err)
+
+	if c.Writer.Written() {
+		a.i.Logger().LogAttrs(c.Request.Context(), slog.LevelError, "inertia handler failed after response was written",
+			slog.String("path", c.Request.URL.Path),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	a.errorHandler(c, err)
+}
+
+// Render renders component, as with Inertia.Render.
+func (a *Adapter) Render(c *gin.Context, component string, props inertia.Props, options ...inertia.RenderOption) error {
+	return a.i.Render(c.Writer, c.Request, component, props, options...)
+}
+
+// RenderTyped renders component with props from a struct, as with
+// inertia.RenderTyped.
+func RenderTyped[P any](a *Adapter, c *gin.Context, component string, props P, options ...inertia.RenderOption) error {
+	return inertia.RenderTyped(a.i, c.Writer, c.Request, component, props, options...)
+}
+
+// RenderErrors responds with validation errors, as with Inertia.RenderErrors.
+func (a *Adapter) RenderErrors(c *gin.Context, errors map[string]any) error {
+	return a.i.RenderErrors(c.Writer, c.Request, errors)
+}
+
+// Share adds a shared prop for the current request.
+func Share(c *gin.Context, key string, prop inertia.Prop) {
+	inertia.Share(c.Request, key, prop)
+}
+
+// ShareMultiple adds shared props for the current request.
+func ShareMultiple(c *gin.Context, props inertia.Props) {
+	inertia.ShareMultiple(c.Request, props)
+}
+
+// Flash stores data for the next request, as with Inertia.Flash.
+func (a *Adapter) Flash(c *gin.Context, key string, value any) error {
+	return a.i.Flash(c.Writer, c.Request, key, value)
+}
+
+// Reflash keeps the current flash data for the next request, as with
+// Inertia.Reflash.
+func (a *Adapter) Reflash(c *gin.Context) error {
+	return a.i.Reflash(c.Writer, c.Request)
+}
+
+// Keep keeps the given flash keys for the next request, as with
+// Inertia.Keep.
+func (a *Adapter) Keep(c *gin.Context, keys ...string) error {
+	return a.i.Keep(c.Writer, c.Request, keys...)
+}
+
+// Redirect redirects to url, as with Inertia.Redirect.
+func (a *Adapter) Redirect(c *gin.Context, url string) {
+	a.i.Redirect(c.Writer, c.Request, url)
+}
+
+// RedirectBack redirects to the previous page, as with Inertia.RedirectBack.
+func (a *Adapter) RedirectBack(c *gin.Context) {
+	a.i.RedirectBack(c.Writer, c.Request)
+}
+
+// Location redirects to an external URL, as with Inertia.Location.
+func (a *Adapter) Location(c *gin.Context, url string) {
+	a.i.Location(c.Writer, c.Request, url)
+}
+
+func (a *Adapter) defaultErrorHandler(c *gin.Context, err error) {
+	a.i.HandleError(c.Writer, c.Request, err)
+}
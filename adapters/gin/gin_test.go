@@ -0,0 +1,160 @@
+package inertiagin_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	inertiagin "github.com/joetifa2003/inertigo/adapters/gin"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newRouter(t *testing.T, options ...inertiagin.Option) (*gin.Engine, *inertiagin.Adapter) {
+	t.Helper()
+
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler, inertia.WithVersion("v2"))
+	require.NoError(t, err)
+
+	a := inertiagin.New(i, options...)
+
+	r := gin.New()
+	r.Use(a.Middleware)
+	r.Use(a.ShareFunc(func(c *gin.Context) (inertia.Props, error) {
+		if c.Query("deny") != "" {
+			return nil, errors.New("denied")
+		}
+		return inertia.Props{"appName": inertia.Value("Inertigo")}, nil
+	}))
+
+	return r, a
+}
+
+func decodePage(t *testing.T, w *httptest.ResponseRecorder) inertia.PageObject {
+	t.Helper()
+
+	var page inertia.PageObject
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+	return page
+}
+
+type showPage struct {
+	ID string `inertia:"id"`
+}
+
+func TestAdapter(t *testing.T) {
+	r, a := newRouter(t)
+
+	reached := false
+	r.GET("/users/:id", a.Handler(func(c *gin.Context) error {
+		reached = true
+		inertiagin.Share(c, "section", inertia.Value("users"))
+		return a.Render(c, "users/Show", inertia.Props{"id": inertia.Value(c.Param("id"))})
+	}))
+	r.GET("/typed/:id", a.Handler(func(c *gin.Context) error {
+		return inertiagin.RenderTyped(a, c, "users/Show", showPage{ID: c.Param("id")})
+	}))
+	r.POST("/users", a.Handler(func(c *gin.Context) error {
+		if err := a.Flash(c, "message", "created"); err != nil {
+			return err
+		}
+		a.Redirect(c, "/users/1")
+		return nil
+	}))
+	r.GET("/fail", a.Handler(func(c *gin.Context) error {
+		return errors.New("boom")
+	}))
+
+	t.Run("renders with shared props", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users/42", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		page := decodePage(t, w)
+		assert.Equal(t, "users/Show", page.Component)
+		assert.Equal(t, "42", page.Props["id"])
+		assert.Equal(t, "Inertigo", page.Props["appName"])
+		assert.Equal(t, "users", page.Props["section"])
+	})
+
+	t.Run("renders typed props", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/typed/7", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "7", decodePage(t, w).Props["id"])
+	})
+
+	t.Run("flashes and redirects", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/users", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "/users/1", w.Header().Get("Location"))
+		assert.NotEmpty(t, w.Result().Cookies())
+	})
+
+	t.Run("maps errors", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		reached = false
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/users/1?deny=1", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.False(t, reached)
+	})
+
+	t.Run("aborts on version mismatch", func(t *testing.T) {
+		reached = false
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		req.Header.Set(inertia.XInertiaVersion, "v1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.False(t, reached)
+	})
+}
+
+func TestAdapter_WithErrorHandler(t *testing.T) {
+	r, a := newRouter(t, inertiagin.WithErrorHandler(func(c *gin.Context, err error) {
+		c.String(http.StatusTeapot, "custom: %v", err)
+	}))
+
+	var errs []*gin.Error
+	r.GET("/", func(c *gin.Context) {
+		c.Next()
+		errs = c.Errors
+	}, a.Handler(func(c *gin.Context) error {
+		return errors.New("boom")
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.Equal(t, "custom: boom", w.Body.String())
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0].Err, "boom")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joetifa2003/inertigo/qjs"
+)
+
+var buildSSRCmd = &cobra.Command{
+	Use:   "build-ssr <ssr.js>",
+	Short: "Precompile an SSR bundle to QuickJS bytecode",
+	Long: `Precompile an SSR bundle to QuickJS bytecode for the qjs SSR engine.
+
+Load the output with qjs.WithBytecodePath or qjs.WithBytecodeFS to skip
+compiling the bundle when the server starts. Bytecode only runs on the
+QuickJS build it was compiled with, so rebuild it whenever you upgrade
+inertigo.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		in := args[0]
+
+		out, _ := cmd.Flags().GetString("out")
+		if out == "" {
+			out = strings.TrimSuffix(in, filepath.Ext(in)) + ".qjsc"
+		}
+
+		source, err := os.ReadFile(in)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		code, err := qjs.Compile(string(source))
+		if err != nil {
+			fmt.Printf("Error compiling %s: %v\n", in, err)
+			os.Exit(1)
+		}
+
+		if err := os.WriteFile(out, code, 0644); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Compiled %s to %s (%d bytes)\n", in, out, len(code))
+	},
+}
+
+func init() {
+	buildSSRCmd.Flags().StringP("out", "o", "", "output path (default: the input path with a .qjsc extension)")
+	rootCmd.AddCommand(buildSSRCmd)
+}
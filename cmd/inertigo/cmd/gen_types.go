@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var genTypesCmd = &cobra.Command{
+	Use:   "gen-types <package>",
+	Short: "Generate TypeScript types for typed page props",
+	Long: `Generate a .d.ts file with the props of typed pages.
+
+The package must export a function that registers the shared props and
+pages with a tsgen.Generator:
+
+	func TypeScriptTypes(g *tsgen.Generator) {
+	    g.Shared(SharedProps{})
+	    g.Page("users/Index", UsersPage{})
+	}
+
+gen-types builds a small program calling it inside the current module and
+runs it with go run, so run it from the module, e.g. with go generate:
+
+	//go:generate inertigo gen-types . -o resources/js/types/pages.d.ts`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+		fn, _ := cmd.Flags().GetString("func")
+
+		if err := genTypes(args[0], fn, out); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Wrote %s\n", out)
+	},
+}
+
+func init() {
+	genTypesCmd.Flags().StringP("out", "o", "pages.d.ts", "output path")
+	genTypesCmd.Flags().StringP("func", "f", "TypeScriptTypes", "function in the package that registers the pages")
+	rootCmd.AddCommand(genTypesCmd)
+}
+
+const genTypesMain = `// Code generated by inertigo gen-types. DO NOT EDIT.
+
+package main
+
+import (
+	"log"
+
+	"github.com/joetifa2003/inertigo/tsgen"
+
+	pages %q
+)
+
+func main() {
+	g := tsgen.New()
+	pages.%s(g)
+
+	if err := g.WriteFile(%q); err != nil {
+		log.Fatal(err)
+	}
+}
+`
+
+// genTypes runs fn from pkg with a tsgen.Generator and writes its output to
+// out. The program doing so lives in a temporary directory in the current
+// module, so pkg may be internal to it.
+func genTypes(pkg, fn, out string) error {
+	list := exec.Command("go", "list", "-f", "{{.ImportPath}}", pkg)
+	list.Stderr = os.Stderr
+	importPath, err := list.Output()
+	if err != nil {
+		return fmt.Errorf("finding package %s: %w", pkg, err)
+	}
+
+	out, err = filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp(".", "inertigo-gen-types-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	source := fmt.Sprintf(genTypesMain, strings.TrimSpace(string(importPath)), fn, out)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0644); err != nil {
+		return err
+	}
+
+	run := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+	if err := run.Run(); err != nil {
+		return fmt.Errorf("running %s.%s: %w", pkg, fn, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+const titles: Record<number, string> = {
+  403: "Forbidden",
+  404: "Page Not Found",
+  500: "Server Error",
+  503: "Service Unavailable",
+};
+
+export default function Error({
+  status,
+  message,
+}: {
+  status: number;
+  message?: string;
+}) {
+  return (
+    <main>
+      <h1>{status}</h1>
+      <p>{titles[status] ?? "Something went wrong"}</p>
+      {message && <pre>{message}</pre>}
+    </main>
+  );
+}
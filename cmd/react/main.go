@@ -37,6 +37,7 @@ func main() {
 			)
 		}),
 		inertia.WithCSRF(true, false), // Enable CSRF, cookieSecure=false for development
+		inertia.WithRecovery(true),
 	)
 	must(err)
 
@@ -44,8 +45,8 @@ func main() {
 
 	mux.Handle(bundler.AssetPrefix(), bundler.Handler())
 
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		err = i.Render(w, r, "index", inertia.Props{
+	mux.HandleFunc("/", i.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return i.Render(w, r, "index", inertia.Props{
 			"message": inertia.Value("Hello, world!"),
 			"reviews": inertia.Deferred(func(ctx context.Context) (any, error) {
 				time.Sleep(time.Second)
@@ -55,25 +56,15 @@ func main() {
 				return time.Now().String(), nil
 			}),
 		})
-		if err != nil {
-			slog.Error(err.Error())
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-			return
-		}
-	})
-
-	mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
-		err = i.Render(w, r, "register", nil)
-		if err != nil {
-			slog.Error(err.Error())
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-			return
-		}
-	})
+	}))
+
+	mux.HandleFunc("/register", i.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return i.Render(w, r, "register", nil)
+	}))
 
-	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/users", i.Handler(func(w http.ResponseWriter, r *http.Request) error {
 		if r.Method != http.MethodPost {
-			return
+			return inertia.NewHTTPError(http.StatusMethodNotAllowed, nil)
 		}
 
 		var body struct {
@@ -113,24 +104,22 @@ func main() {
 			}
 		}
 
-		err := i.RenderErrors(w, r, errors)
-		if err != nil {
-			slog.Error(err.Error())
-			return
+		if len(errors) != 0 {
+			return inertia.ValidationErrors(errors)
 		}
 
 		if inertia.IsPrecognition(r) {
-			return
+			inertia.PrecognitionSuccess(w, r)
+			return nil
 		}
 
-		if len(errors) != 0 {
-			return
+		if err := i.Flash(w, r, "success", "User created successfully!"); err != nil {
+			return err
 		}
 
-		i.Flash(w, r, "success", "User created successfully!")
-
 		i.Redirect(w, r, "/")
-	})
+		return nil
+	}))
 
 	i.Logger().Log(context.Background(), slog.LevelInfo, "starting server", slog.String("url", "http://localhost:8001"))
 
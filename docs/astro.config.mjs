@@ -28,7 +28,9 @@ export default defineConfig({
                         { label: 'The Inertia Instance', slug: 'core-concepts/the-inertia-instance' },
                         { label: 'Rendering Pages', slug: 'core-concepts/rendering-pages' },
                         { label: 'Middleware', slug: 'core-concepts/middleware' },
+                        { label: 'Error Handling', slug: 'core-concepts/error-handling' },
                         { label: 'Root Template', slug: 'core-concepts/root-template' },
+                        { label: 'Router Adapters', slug: 'core-concepts/router-adapters' },
                     ],
                 },
                 {
@@ -41,6 +43,7 @@ export default defineConfig({
                         { label: 'Always', slug: 'props/always' },
                         { label: 'Once', slug: 'props/once' },
                         { label: 'Scroll', slug: 'props/scroll' },
+                        { label: 'Typed Props', slug: 'props/typed' },
                     ],
                 },
                 {
@@ -60,6 +63,7 @@ export default defineConfig({
                         { label: 'Precognition', slug: 'advanced/precognition' },
                         { label: 'Partial Reloads', slug: 'advanced/partial-reloads' },
                         { label: 'Render Options', slug: 'advanced/render-options' },
+                        { label: 'TypeScript Types', slug: 'advanced/typescript-types' },
                     ],
                 },
                 {
@@ -67,6 +71,7 @@ export default defineConfig({
                     items: [
                         { label: 'Vite', slug: 'bundlers/vite' },
                         { label: 'QuickJS SSR', slug: 'bundlers/quickjs-ssr' },
+                        { label: 'Node.js / Bun SSR', slug: 'bundlers/sidecar-ssr' },
                     ],
                 },
             ],
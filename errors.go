@@ -0,0 +1,160 @@
+package inertia
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ErrNotFound is the error for a missing resource. HandleError responds to
+// it with the error page and 404 Not Found.
+var ErrNotFound = errors.New("inertia: not found")
+
+// HTTPError is an error shown with a given HTTP status by HandleError.
+type HTTPError struct {
+	Status int
+	Err    error
+}
+
+// NewHTTPError returns an HTTPError for err with status.
+func NewHTTPError(status int, err error) *HTTPError {
+	return &HTTPError{Status: status, Err: err}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Status)
+	}
+	return e.Err.Error()
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors are validation messages by field, returned as an error.
+// HandleError sends them to the client with RenderErrors.
+type ValidationErrors map[string]any
+
+func (e ValidationErrors) Error() string {
+	return fmt.Sprintf("inertia: validation failed: %s", strings.Join(slices.Sorted(maps.Keys(e)), ", "))
+}
+
+// ErrorStatus returns the HTTP status HandleError uses for err: the status of
+// an HTTPError, 422 Unprocessable Entity for ValidationErrors, 404 Not Found
+// for ErrNotFound and 500 Internal Server Error otherwise.
+func ErrorStatus(err error) int {
+	var httpErr *HTTPError
+	var validationErrs ValidationErrors
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr.Status
+	case errors.As(err, &validationErrs):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// HandlerFunc is an http.HandlerFunc that returns an error.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Handler converts fn to an http.HandlerFunc, passing the errors it returns
+// to HandleError.
+func (i *Inertia) Handler(fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			i.HandleError(w, r, err)
+		}
+	}
+}
+
+// HandleError writes the response for an error returned by a handler, for
+// both Inertia requests and full page loads. ValidationErrors are sent with
+// RenderErrors. Other errors are shown with RenderError, with the status from
+// ErrorStatus; server errors are logged.
+//
+// HandleError must be called before anything is written to w.
+func (i *Inertia) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErrs ValidationErrors
+	if errors.As(err, &validationErrs) {
+		if err := i.RenderErrors(w, r, validationErrs); err != nil {
+			i.logger.LogAttrs(r.Context(), slog.LevelError, "failed to render validation errors",
+				slog.String("path", r.URL.Path),
+				slog.String("error", err.Error()),
+			)
+		}
+		return
+	}
+
+	status := ErrorStatus(err)
+	if status >= http.StatusInternalServerError {
+		i.logger.LogAttrs(r.Context(), slog.LevelError, "inertia handler failed",
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	i.RenderError(w, r, status, err)
+}
+
+// ErrorPropsFunc returns extra props for the error page rendered for err
+// with status.
+type ErrorPropsFunc func(r *http.Request, status int, err error) Props
+
+// RenderError renders the error page, the component set with
+// WithErrorComponent, with status as the response status. The page gets a
+// "status" prop and, in dev, a "message" prop with the text of err, which may
+// be nil. Messages are left out in production, where they could expose
+// internals. Props from WithErrorProps are added to these.
+//
+// If the page cannot be rendered, e.g. because there is no root template for
+// a full page load, a plain text response is written instead, unless part of
+// the response has already gone out.
+func (i *Inertia) RenderError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	tw := NewTrackingWriter(w)
+
+	if r.Header.Get(XInertia) != "true" && i.rootTemplate == nil {
+		i.writeErrorText(tw, r, status)
+		return
+	}
+
+	props := Props{
+		"status": Value(status),
+	}
+	if err != nil && i.bundler.IsDev() {
+		props["message"] = Value(err.Error())
+	}
+	if i.errorProps != nil {
+		maps.Copy(props, i.errorProps(r, status, err))
+	}
+
+	renderErr := i.Render(tw, r, i.errorComponent, props, WithStatus(status))
+	if renderErr != nil {
+		i.logger.LogAttrs(r.Context(), slog.LevelError, "failed to render error page",
+			slog.String("path", r.URL.Path),
+			slog.String("error", renderErr.Error()),
+		)
+		i.writeErrorText(tw, r, status)
+	}
+}
+
+// writeErrorText writes a plain text error response, or logs that it cannot
+// if the response has started.
+func (i *Inertia) writeErrorText(w *TrackingWriter, r *http.Request, status int) {
+	if w.Written() {
+		i.logger.LogAttrs(r.Context(), slog.LevelError, "error response not written, response already started",
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+		)
+		return
+	}
+	http.Error(w, http.StatusText(status), status)
+}
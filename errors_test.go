@@ -0,0 +1,241 @@
+package inertia_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{errors.New("boom"), http.StatusInternalServerError},
+		{inertia.ErrNotFound, http.StatusNotFound},
+		{fmt.Errorf("user 1: %w", inertia.ErrNotFound), http.StatusNotFound},
+		{inertia.NewHTTPError(http.StatusForbidden, nil), http.StatusForbidden},
+		{fmt.Errorf("wrapped: %w", inertia.NewHTTPError(http.StatusServiceUnavailable, errors.New("down"))), http.StatusServiceUnavailable},
+		{inertia.ValidationErrors{"name": "required"}, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tt.status, inertia.ErrorStatus(tt.err))
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler, inertia.WithRootHtmlPathFS(fstest.MapFS{
+		"index.html": {Data: []byte(`<html><body>{{ .InertiaBody }}</body></html>`)},
+	}, "index.html"))
+	require.NoError(t, err)
+
+	handlerErr := errors.New("boom")
+	handler := i.Middleware(i.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		inertia.Share(r, "appName", inertia.Value("Inertigo"))
+		return handlerErr
+	}))
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Inertia request renders the error page", func(t *testing.T) {
+		handlerErr = inertia.ErrNotFound
+
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := serve(req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "true", w.Header().Get(inertia.XInertia))
+
+		var page inertia.PageObject
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		assert.Equal(t, "Error", page.Component)
+		assert.Equal(t, "/users/1", page.URL)
+		assert.EqualValues(t, http.StatusNotFound, page.Props["status"])
+		assert.Equal(t, "Inertigo", page.Props["appName"])
+	})
+
+	t.Run("full load renders the error page", func(t *testing.T) {
+		handlerErr = errors.New("boom")
+
+		w := serve(httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, w.Body.String(), `data-page=`)
+		assert.Contains(t, w.Body.String(), `&#34;component&#34;:&#34;Error&#34;`)
+	})
+
+	t.Run("HTTPError sets the status", func(t *testing.T) {
+		handlerErr = inertia.NewHTTPError(http.StatusForbidden, errors.New("not yours"))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := serve(req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("validation errors redirect back", func(t *testing.T) {
+		handlerErr = fmt.Errorf("creating user: %w", inertia.ValidationErrors{"name": "Name is required"})
+
+		req := httptest.NewRequest("POST", "/users", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		req.Header.Set("Referer", "/register")
+		w := serve(req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "/register", w.Header().Get("Location"))
+	})
+
+	t.Run("validation errors for precognition", func(t *testing.T) {
+		handlerErr = inertia.ValidationErrors{"name": "Name is required"}
+
+		req := httptest.NewRequest("POST", "/users", nil)
+		req.Header.Set(inertia.HeaderPrecognition, "true")
+		w := serve(req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("no error", func(t *testing.T) {
+		handlerErr = nil
+
+		w := serve(httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Zero(t, w.Body.Len())
+	})
+}
+
+func TestHandleError_WithoutRootTemplate(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	i.HandleError(w, httptest.NewRequest("GET", "/", nil), inertia.ErrNotFound)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Not Found\n", w.Body.String())
+}
+
+func TestHandleError_UnknownErrorPage(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(false))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler, inertia.WithPages(inertia.PagesFromList("Home")))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(inertia.XInertia, "true")
+	w := httptest.NewRecorder()
+	i.HandleError(w, req, errors.New("boom"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "Internal Server Error\n", w.Body.String())
+}
+
+func TestRenderError(t *testing.T) {
+	renderError := func(t *testing.T, dev bool, options ...inertia.InertiaOption) (*httptest.ResponseRecorder, inertia.PageObject) {
+		t.Helper()
+
+		bundler, err := vite.New(nil, vite.WithDevMode(dev))
+		require.NoError(t, err)
+
+		i, err := inertia.New(bundler, options...)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/admin", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+		i.RenderError(w, req, http.StatusServiceUnavailable, errors.New("database is down"))
+
+		var page inertia.PageObject
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		return w, page
+	}
+
+	t.Run("shows the message in dev", func(t *testing.T) {
+		w, page := renderError(t, true)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "Error", page.Component)
+		assert.EqualValues(t, http.StatusServiceUnavailable, page.Props["status"])
+		assert.Equal(t, "database is down", page.Props["message"])
+	})
+
+	t.Run("hides the message in production", func(t *testing.T) {
+		w, page := renderError(t, false)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.EqualValues(t, http.StatusServiceUnavailable, page.Props["status"])
+		assert.NotContains(t, page.Props, "message")
+	})
+
+	t.Run("custom component and props", func(t *testing.T) {
+		_, page := renderError(t, false,
+			inertia.WithErrorComponent("errors/Show"),
+			inertia.WithErrorProps(func(r *http.Request, status int, err error) inertia.Props {
+				return inertia.Props{
+					"path":    inertia.Value(r.URL.Path),
+					"message": inertia.Value("We'll be back soon."),
+				}
+			}),
+		)
+
+		assert.Equal(t, "errors/Show", page.Component)
+		assert.EqualValues(t, http.StatusServiceUnavailable, page.Props["status"])
+		assert.Equal(t, "/admin", page.Props["path"])
+		assert.Equal(t, "We'll be back soon.", page.Props["message"])
+	})
+
+	t.Run("does not write over a partial page", func(t *testing.T) {
+		var logs bytes.Buffer
+		bundler, err := vite.New(nil)
+		require.NoError(t, err)
+
+		// The template fails after the body has been written.
+		i, err := inertia.New(bundler,
+			inertia.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+			inertia.WithRootHtmlPathFS(fstest.MapFS{
+				"index.html": {Data: []byte(`<body>{{ .InertiaBody }}{{ index .InertiaHead 5 }}</body>`)},
+			}, "index.html"),
+		)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		i.RenderError(w, httptest.NewRequest("GET", "/", nil), http.StatusInternalServerError, errors.New("boom"))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.True(t, strings.HasPrefix(w.Body.String(), `<body><div id="app"`))
+		assert.NotContains(t, w.Body.String(), http.StatusText(http.StatusInternalServerError))
+		assert.Contains(t, logs.String(), "failed to render error page")
+		assert.Contains(t, logs.String(), "response already started")
+	})
+}
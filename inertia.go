@@ -6,12 +6,15 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io/fs"
 	"log/slog"
+	"maps"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -27,20 +30,23 @@ const (
 
 // inertiaContext holds all accumulated data for a request.
 type inertiaContext struct {
-	shared Props          // Shared props for current request
-	flash  map[string]any // Flash props from previous request (read)
+	shared  Props          // Shared props for current request
+	flash   map[string]any // Flash props from previous request (read)
+	flashed map[string]any // Flash props for the next request (written)
 }
 
 func newInertiaContext() inertiaContext {
 	return inertiaContext{
-		shared: make(Props),
-		flash:  make(map[string]any),
+		shared:  make(Props),
+		flash:   make(map[string]any),
+		flashed: make(map[string]any),
 	}
 }
 
 var inertiaContextPool = pool.NewPool(newInertiaContext, pool.WithPoolBeforeGet[inertiaContext](func(ic inertiaContext) {
 	clear(ic.shared)
 	clear(ic.flash)
+	clear(ic.flashed)
 }))
 
 type Inertia struct {
@@ -54,11 +60,26 @@ type Inertia struct {
 	ssrEngineFactory func() (SSREngine, error)
 	ssrEngine        SSREngine
 	ssrInitLock      sync.Mutex
+	ssrTimeout       time.Duration
+	ssrFallback      bool
+	ssrErrorHandler  func(r *http.Request, page *PageObject, err error)
+	ssrBreaker       *ssrCircuitBreaker
+	ssrCache         *ssrCache
+	streaming        bool
 
 	session Session
 
 	csrfEnabled bool
 	csrfConfig  csrfConfig
+
+	propConcurrency int
+
+	pages *pageRegistry
+
+	errorComponent string
+	errorProps     ErrorPropsFunc
+
+	recovery bool
 }
 
 type inertiaConfig struct {
@@ -67,6 +88,12 @@ type inertiaConfig struct {
 
 	ssrEnabled       bool
 	ssrEngineFactory func() (SSREngine, error)
+	ssrTimeout       time.Duration
+	ssrFallback      bool
+	ssrErrorHandler  func(r *http.Request, page *PageObject, err error)
+	ssrBreaker       *ssrCircuitBreaker
+	ssrCache         *ssrCache
+	streaming        bool
 
 	logger  Logger
 	version string
@@ -75,6 +102,15 @@ type inertiaConfig struct {
 
 	csrfEnabled bool
 	csrfConfig  csrfConfig
+
+	propConcurrency int
+
+	pages PageSource
+
+	errorComponent string
+	errorProps     ErrorPropsFunc
+
+	recovery bool
 }
 
 type InertiaOption func(config *inertiaConfig) error
@@ -104,6 +140,82 @@ func WithSSR(enabled bool, engineFactory func() (SSREngine, error)) InertiaOptio
 	}
 }
 
+// WithSSRTimeout limits how long a single server-side render may take.
+// The render is cancelled once the timeout elapses. Zero means no timeout.
+func WithSSRTimeout(timeout time.Duration) InertiaOption {
+	return func(config *inertiaConfig) error {
+		config.ssrTimeout = timeout
+		return nil
+	}
+}
+
+// WithSSRFallback makes full page loads fall back to client-side rendering
+// when the SSR engine fails or times out. The failure is logged and the page
+// is served with the plain data-page body instead of returning an error.
+func WithSSRFallback(enabled bool) InertiaOption {
+	return func(config *inertiaConfig) error {
+		config.ssrFallback = enabled
+		return nil
+	}
+}
+
+// WithSSRErrorHandler sets a hook that is called whenever server-side
+// rendering fails, e.g. to report the error to an error tracker.
+// It is called whether or not WithSSRFallback is enabled.
+func WithSSRErrorHandler(handler func(r *http.Request, page *PageObject, err error)) InertiaOption {
+	return func(config *inertiaConfig) error {
+		config.ssrErrorHandler = handler
+		return nil
+	}
+}
+
+// WithSSRCircuitBreaker stops calling the SSR engine after threshold
+// consecutive render failures. While the circuit is open, full page loads
+// are client-side rendered. After cooldown a single probe render is sent to
+// the engine; if it succeeds the circuit closes again.
+// Use Inertia.SSRHealth to inspect the breaker state.
+func WithSSRCircuitBreaker(threshold int, cooldown time.Duration) InertiaOption {
+	return func(config *inertiaConfig) error {
+		if threshold <= 0 {
+			return fmt.Errorf("ssr circuit breaker threshold must be positive, got %d", threshold)
+		}
+		config.ssrBreaker = newSSRCircuitBreaker(threshold, cooldown)
+		return nil
+	}
+}
+
+// WithSSRCache caches server-side rendered pages in memory, keeping at most
+// maxEntries of them in least recently used order. Entries expire after ttl;
+// zero means they only leave the cache when evicted or invalidated.
+// Pages are keyed by a hash of the whole page object, so a cached page is
+// only served for a render that would produce the same HTML. Use
+// WithSSRCacheKey to share an entry across pages and WithoutSSRCache to opt
+// a render out.
+func WithSSRCache(maxEntries int, ttl time.Duration) InertiaOption {
+	return func(config *inertiaConfig) error {
+		if maxEntries <= 0 {
+			return fmt.Errorf("ssr cache size must be positive, got %d", maxEntries)
+		}
+		config.ssrCache = newSSRCache(maxEntries, ttl)
+		return nil
+	}
+}
+
+// WithStreaming streams server-side rendered full page loads. Everything
+// in the root template before {{ .InertiaHead }}, such as asset tags, is
+// sent right away so the browser can start fetching scripts and styles;
+// the rest follows once the SSR render finishes. Put {{ .InertiaHead }}
+// after the asset tags to get the most out of it.
+//
+// Because the response has already started, a failed SSR render always
+// falls back to client-side rendering, regardless of WithSSRFallback.
+func WithStreaming(enabled bool) InertiaOption {
+	return func(config *inertiaConfig) error {
+		config.streaming = enabled
+		return nil
+	}
+}
+
 func WithLogger(logger Logger) InertiaOption {
 	return func(config *inertiaConfig) error {
 		config.logger = logger
@@ -169,6 +281,57 @@ func WithCSRF(enabled bool, cookieSecure bool) InertiaOption {
 	}
 }
 
+// WithPages sets the list of page components, e.g. from PagesFromDir or
+// vite.Bundler.Pages. Render then returns ErrUnknownPage for any other
+// component. In dev the list is reloaded when a component is missing, so
+// new pages are picked up; in production New fails if the list cannot be
+// loaded or is empty.
+func WithPages(source PageSource) InertiaOption {
+	return func(config *inertiaConfig) error {
+		config.pages = source
+		return nil
+	}
+}
+
+// WithPropConcurrency sets how many prop resolvers may run at the same time
+// while rendering a page. A value of 0 or less removes the limit.
+// By default props are resolved one after another.
+func WithPropConcurrency(n int) InertiaOption {
+	return func(config *inertiaConfig) error {
+		config.propConcurrency = n
+		return nil
+	}
+}
+
+// WithErrorComponent sets the page component RenderError renders, "Error"
+// by default.
+func WithErrorComponent(component string) InertiaOption {
+	return func(config *inertiaConfig) error {
+		config.errorComponent = component
+		return nil
+	}
+}
+
+// WithErrorProps adds the props fn returns to the error page, next to
+// "status" and, in dev, "message". Props with the same key replace them.
+func WithErrorProps(fn ErrorPropsFunc) InertiaOption {
+	return func(config *inertiaConfig) error {
+		config.errorProps = fn
+		return nil
+	}
+}
+
+// WithRecovery recovers panics in Middleware and the handlers it wraps. The
+// panic and its stack are logged and the client gets a 500 response: the
+// error page from RenderError, or in dev, for Inertia requests, a plain HTML
+// response with the stack, which the client shows in a modal.
+func WithRecovery(enabled bool) InertiaOption {
+	return func(config *inertiaConfig) error {
+		config.recovery = enabled
+		return nil
+	}
+}
+
 // Logger defines the interface for structured logging.
 // Compatible with slog.Logger.
 type Logger interface {
@@ -181,7 +344,10 @@ type Logger interface {
 func New(b Bundler, options ...InertiaOption) (*Inertia, error) {
 	var err error
 
-	config := &inertiaConfig{}
+	config := &inertiaConfig{
+		propConcurrency: 1,
+		errorComponent:  "Error",
+	}
 
 	for _, option := range options {
 		err = option(config)
@@ -194,15 +360,21 @@ func New(b Bundler, options ...InertiaOption) (*Inertia, error) {
 		bundler:          b,
 		ssrEnabled:       config.ssrEnabled,
 		ssrEngineFactory: config.ssrEngineFactory,
+		ssrTimeout:       config.ssrTimeout,
+		ssrFallback:      config.ssrFallback,
+		ssrErrorHandler:  config.ssrErrorHandler,
+		ssrBreaker:       config.ssrBreaker,
+		ssrCache:         config.ssrCache,
+		streaming:        config.streaming,
 		logger:           config.logger,
 		version:          config.version,
 		session:          config.session,
 		csrfEnabled:      config.csrfEnabled,
 		csrfConfig:       config.csrfConfig,
-	}
-
-	if i.session == nil {
-		i.session = NewMemorySession("sid")
+		propConcurrency:  config.propConcurrency,
+		errorComponent:   config.errorComponent,
+		errorProps:       config.errorProps,
+		recovery:         config.recovery,
 	}
 
 	// Parse root template with bundler's template functions
@@ -224,9 +396,41 @@ func New(b Bundler, options ...InertiaOption) (*Inertia, error) {
 		i.logger = slog.New(slog.DiscardHandler)
 	}
 
+	if i.session == nil {
+		i.session = NewMemorySession("sid", WithMemoryLogger(i.logger))
+	}
+
+	if config.pages != nil {
+		i.pages = newPageRegistry(config.pages, b.IsDev())
+		if err := i.loadPages(); err != nil {
+			return nil, err
+		}
+	}
+
 	return &i, nil
 }
 
+// loadPages loads the page list. In production a list that fails to load
+// or is empty is an error; in dev it is logged and reloaded on Render.
+func (i *Inertia) loadPages() error {
+	err := i.pages.load()
+	if err == nil && len(i.pages.pages) == 0 {
+		err = errors.New("no pages found")
+	}
+	if err == nil {
+		return nil
+	}
+
+	if !i.bundler.IsDev() {
+		return fmt.Errorf("inertia: loading pages: %w", err)
+	}
+
+	i.logger.LogAttrs(context.Background(), slog.LevelWarn, "failed to load pages",
+		slog.String("error", err.Error()),
+	)
+	return nil
+}
+
 // Share adds a prop to the request context for the current request.
 // Shared props have lower priority than page props and flash props.
 func Share(r *http.Request, key string, prop Prop) {
@@ -244,13 +448,66 @@ func ShareMultiple(r *http.Request, props Props) {
 
 // Flash stores data for the next request only.
 // Flash data has the highest priority and overrides both shared and page props.
+// Successive calls in a request add to the data flashed before, replacing
+// values with the same key.
 func (i *Inertia) Flash(w http.ResponseWriter, r *http.Request, key string, value any) error {
 	return i.FlashMultiple(w, r, map[string]any{key: value})
 }
 
 // FlashMultiple stores multiple key-value pairs for the next request.
 func (i *Inertia) FlashMultiple(w http.ResponseWriter, r *http.Request, data map[string]any) error {
-	return i.session.Flash(w, r, data)
+	return i.flash(w, r, data, true)
+}
+
+// Reflash keeps all of the current request's flash data for the next
+// request too, e.g. when redirecting again before it has been shown.
+func (i *Inertia) Reflash(w http.ResponseWriter, r *http.Request) error {
+	ic := getInertiaContext(r)
+	if ic == nil {
+		return nil
+	}
+	return i.Keep(w, r, slices.Collect(maps.Keys(ic.flash))...)
+}
+
+// Keep keeps the given keys of the current request's flash data for the
+// next request too. Values flashed in this request take precedence over
+// kept ones.
+func (i *Inertia) Keep(w http.ResponseWriter, r *http.Request, keys ...string) error {
+	ic := getInertiaContext(r)
+	if ic == nil {
+		return nil
+	}
+
+	kept := make(map[string]any, len(keys))
+	for _, key := range keys {
+		if value, ok := ic.flash[key]; ok {
+			kept[key] = value
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	return i.flash(w, r, kept, false)
+}
+
+// flash merges data into the values flashed so far in the request and
+// passes them all to the session. Without Middleware there is nothing to
+// merge with, so data is passed as is.
+func (i *Inertia) flash(w http.ResponseWriter, r *http.Request, data map[string]any, overwrite bool) error {
+	ic := getInertiaContext(r)
+	if ic == nil {
+		return i.session.Flash(w, r, data)
+	}
+
+	for key, value := range data {
+		if _, exists := ic.flashed[key]; exists && !overwrite {
+			continue
+		}
+		ic.flashed[key] = value
+	}
+
+	return i.session.Flash(w, r, maps.Clone(ic.flashed))
 }
 
 // getInertiaContext retrieves the inertiaContext from the request.
@@ -290,7 +547,7 @@ func (i *Inertia) getSSREngine(ctx context.Context) (SSREngine, error) {
 				if err != nil {
 					return nil, err
 				}
-				i.ssrEngine = engine
+				i.ssrEngine = i.wrapSSREngine(engine)
 				i.logger.LogAttrs(
 					ctx, slog.LevelInfo,
 					"dev mode enabled, starting bundler ssr engine",
@@ -302,7 +559,7 @@ func (i *Inertia) getSSREngine(ctx context.Context) (SSREngine, error) {
 				if err != nil {
 					return nil, err
 				}
-				i.ssrEngine = engine
+				i.ssrEngine = i.wrapSSREngine(engine)
 				i.logger.LogAttrs(
 					ctx, slog.LevelInfo,
 					"ssr engine started",
@@ -316,6 +573,47 @@ func (i *Inertia) getSSREngine(ctx context.Context) (SSREngine, error) {
 	return i.ssrEngine, nil
 }
 
+// wrapSSREngine applies the configured SSR middleware layers to engine.
+func (i *Inertia) wrapSSREngine(engine SSREngine) SSREngine {
+	if i.ssrBreaker != nil {
+		i.ssrBreaker.engine = engine
+		engine = i.ssrBreaker
+	}
+	return engine
+}
+
+// SSRHealth returns a snapshot of the SSR circuit breaker configured with
+// WithSSRCircuitBreaker. Without a breaker the state is always closed.
+func (i *Inertia) SSRHealth() SSRHealth {
+	if i.ssrBreaker == nil {
+		return SSRHealth{State: SSRCircuitClosed}
+	}
+	return i.ssrBreaker.snapshot()
+}
+
+// InvalidateSSRCache removes the cached renders of the given components.
+func (i *Inertia) InvalidateSSRCache(components ...string) {
+	if i.ssrCache != nil {
+		i.ssrCache.invalidate(components)
+	}
+}
+
+// InvalidateSSRCacheKey removes the cached render stored under a key set
+// with WithSSRCacheKey.
+func (i *Inertia) InvalidateSSRCacheKey(key string) {
+	if i.ssrCache != nil {
+		i.ssrCache.invalidateKey(i.ssrCache.customKey(i.version, key))
+	}
+}
+
+// PurgeSSRCache removes every cached render, e.g. after reloading the SSR
+// bundle.
+func (i *Inertia) PurgeSSRCache() {
+	if i.ssrCache != nil {
+		i.ssrCache.purge()
+	}
+}
+
 func (i *Inertia) Logger() Logger {
 	return i.logger
 }
@@ -354,22 +652,167 @@ var processedPropsPool = pool.NewPool(newProcessedProps, pool.WithPoolBeforeGet[
 func (i *Inertia) processProps(ctx context.Context, props Props, headers *inertiaHeaders) (*processedProps, error) {
 	p := processedPropsPool.Get()
 
-	for key, prop := range props {
-		if prop.shouldInclude(key, headers) {
-			resolved, err := prop.resolve(ctx)
+	var groups []string
+	var included []includedProp
+	collectProps(props, "", headers, p, &groups, &included)
+
+	resolved, err := i.resolveProps(ctx, included)
+	if err != nil {
+		return p, err
+	}
+
+	// Groups come before their children, so every group exists by the time
+	// its props are set.
+	for _, path := range groups {
+		setPropPath(p.finalProps, path, map[string]any{})
+	}
+	for idx, prop := range included {
+		setPropPath(p.finalProps, prop.path, resolved[idx])
+	}
+
+	return p, nil
+}
+
+// includedProp is a prop to resolve and the dot-separated path it goes to.
+type includedProp struct {
+	path string
+	prop Prop
+}
+
+// collectProps walks props depth-first, appending the nested groups and the
+// props to resolve. It walks in key order so the metadata slices
+// (mergeProps, deferredProps, ...) are the same on every render.
+func collectProps(props Props, prefix string, headers *inertiaHeaders, p *processedProps, groups *[]string, included *[]includedProp) {
+	for _, key := range slices.Sorted(maps.Keys(props)) {
+		prop := props[key]
+		path := prefix + key
+
+		if nested, ok := prop.(Props); ok {
+			if nested.shouldInclude(path, headers) {
+				*groups = append(*groups, path)
+				collectProps(nested, path+".", headers, p, groups, included)
+			}
+			continue
+		}
+
+		if prop.shouldInclude(path, headers) {
+			*included = append(*included, includedProp{path: path, prop: prop})
+		}
+
+		prop.modifyProcessedProps(path, headers, p)
+	}
+}
+
+// setPropPath sets the value at a dot-separated path, creating the parent
+// maps as needed.
+func setPropPath(props map[string]any, path string, value any) {
+	for {
+		key, rest, nested := strings.Cut(path, ".")
+		if !nested {
+			props[key] = value
+			return
+		}
+
+		child, ok := props[key].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			props[key] = child
+		}
+		props, path = child, rest
+	}
+}
+
+// resolveProps resolves props, running at most propConcurrency resolvers
+// at once. The returned values are in the same order as props. The first
+// error cancels the context seen by the other resolvers and is returned.
+func (i *Inertia) resolveProps(ctx context.Context, props []includedProp) ([]any, error) {
+	values := make([]any, len(props))
+
+	if i.propConcurrency == 1 || len(props) <= 1 {
+		for idx, prop := range props {
+			value, err := prop.prop.resolve(ctx)
 			if err != nil {
-				return p, err
+				return nil, err
 			}
-			p.finalProps[key] = resolved
+			values[idx] = value
 		}
+		return values, nil
+	}
 
-		prop.modifyProcessedProps(key, headers, p)
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	limit := i.propConcurrency
+	if limit <= 0 || limit > len(props) {
+		limit = len(props)
 	}
 
-	return p, nil
+	var (
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, limit)
+		errOnce   sync.Once
+		firstErr  error
+		panicOnce sync.Once
+		panicVal  any
+	)
+
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel(err)
+		})
+	}
+
+	started := 0
+	for idx, prop := range props {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		started++
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+			defer func() {
+				// Re-panic on the caller's goroutine so panics from
+				// resolvers behave the same as in sequential mode.
+				if v := recover(); v != nil {
+					panicOnce.Do(func() { panicVal = v })
+					fail(fmt.Errorf("prop %q panicked: %v", prop.path, v))
+				}
+			}()
+
+			value, err := prop.prop.resolve(ctx)
+			if err != nil {
+				fail(err)
+				return
+			}
+			values[idx] = value
+		})
+	}
+
+	wg.Wait()
+
+	if panicVal != nil {
+		panic(panicVal)
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// The parent context was cancelled before every resolver could start.
+	if started < len(props) {
+		return nil, context.Cause(ctx)
+	}
+
+	return values, nil
 }
 
-func (i *Inertia) renderJSON(w http.ResponseWriter, r *http.Request, page *PageObject) error {
+func (i *Inertia) renderJSON(w http.ResponseWriter, r *http.Request, page *PageObject, config *renderConfig) error {
 	i.logger.LogAttrs(r.Context(),
 		slog.LevelDebug, "inertia request detected, rendering json",
 		slog.String("component", page.Component),
@@ -379,60 +822,146 @@ func (i *Inertia) renderJSON(w http.ResponseWriter, r *http.Request, page *PageO
 	w.Header().Set(XInertia, "true")
 	w.Header().Set("Vary", XInertia)
 	w.Header().Set("Content-Type", "application/json")
+	config.writeStatus(w)
 	return json.NewEncoder(w).Encode(page)
 }
 
-func (i *Inertia) renderHTML(w http.ResponseWriter, r *http.Request, page *PageObject) error {
+func (i *Inertia) renderHTML(w http.ResponseWriter, r *http.Request, page *PageObject, config *renderConfig) error {
 	i.logger.LogAttrs(
 		r.Context(), slog.LevelDebug, "rendering full page",
 		slog.String("component", page.Component),
 		slog.String("url", page.URL),
 	)
 
-	pageObjectJSON, err := json.Marshal(page)
+	if i.streaming && i.ssrEnabled {
+		return i.streamHTML(w, r, page, config)
+	}
+
+	head, body, err := i.renderPageBody(r, page, config, i.ssrFallback)
 	if err != nil {
 		return err
 	}
 
-	var head []string
-	var body string
+	view := RootHtmlView{
+		InertiaHead: template.HTML(strings.Join(head, "\n")),
+		InertiaBody: template.HTML(body),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	config.writeStatus(w)
+	return i.rootTemplate.Execute(w, view)
+}
 
+// renderPageBody renders the head tags and app body of a full page load,
+// server-side when SSR is enabled. If SSR fails and fallback is set, the
+// page is client-side rendered instead of returning the error.
+func (i *Inertia) renderPageBody(r *http.Request, page *PageObject, config *renderConfig, fallback bool) ([]string, string, error) {
 	if i.ssrEnabled {
-		t1 := time.Now()
-		engine, err := i.getSSREngine(r.Context())
-		if err != nil {
-			return err
+		renderedPage, err := i.renderSSRCached(r, page, config)
+		switch {
+		case errors.Is(err, ErrSSRCircuitOpen):
+			i.logger.LogAttrs(
+				r.Context(), slog.LevelDebug,
+				"ssr circuit breaker is open, rendering client-side",
+				slog.String("component", page.Component),
+			)
+		case err != nil:
+			if i.ssrErrorHandler != nil {
+				i.ssrErrorHandler(r, page, err)
+			}
+			if !fallback {
+				return nil, "", err
+			}
+
+			i.logger.LogAttrs(
+				r.Context(), slog.LevelError,
+				"ssr render failed, falling back to client-side rendering",
+				slog.String("component", page.Component),
+				slog.String("error", err.Error()),
+			)
+		default:
+			return renderedPage.Head, renderedPage.Body, nil
 		}
+	}
+
+	pageObjectJSON, err := json.Marshal(page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	inertiaBodyBuf := bytes.NewBuffer(nil)
+	err = inertiaBodyTemplate.Execute(inertiaBodyBuf, string(pageObjectJSON))
+	if err != nil {
+		return nil, "", err
+	}
 
-		renderedPage, err := engine.Render(*page)
+	return nil, inertiaBodyBuf.String(), nil
+}
+
+// renderSSRCached serves the page from the SSR cache when possible, and
+// caches successful renders.
+func (i *Inertia) renderSSRCached(r *http.Request, page *PageObject, config *renderConfig) (RenderedPage, error) {
+	if i.ssrCache == nil || config.noSSRCache {
+		return i.renderSSR(r, page)
+	}
+
+	var key string
+	if config.ssrCacheKey != "" {
+		key = i.ssrCache.customKey(i.version, config.ssrCacheKey)
+	} else {
+		var err error
+		key, err = i.ssrCache.pageKey(page)
 		if err != nil {
-			return err
+			return RenderedPage{}, err
 		}
+	}
+
+	if renderedPage, ok := i.ssrCache.get(key); ok {
 		i.logger.LogAttrs(
-			r.Context(), slog.LevelInfo,
-			"ssr engine rendered page",
-			slog.String("engine", engine.Name()),
-			slog.String("dur", time.Since(t1).String()),
+			r.Context(), slog.LevelDebug,
+			"ssr cache hit",
+			slog.String("component", page.Component),
 		)
+		return renderedPage, nil
+	}
 
-		head = renderedPage.Head
-		body = renderedPage.Body
-	} else {
-		inertiaBodyBuf := bytes.NewBuffer(nil)
-		err = inertiaBodyTemplate.Execute(inertiaBodyBuf, string(pageObjectJSON))
-		if err != nil {
-			return err
-		}
-		body = inertiaBodyBuf.String()
+	renderedPage, err := i.renderSSR(r, page)
+	if err != nil {
+		return RenderedPage{}, err
 	}
 
-	view := RootHtmlView{
-		InertiaHead: template.HTML(strings.Join(head, "\n")),
-		InertiaBody: template.HTML(body),
+	i.ssrCache.set(key, page.Component, renderedPage)
+	return renderedPage, nil
+}
+
+// renderSSR renders the page with the SSR engine, applying the configured
+// render timeout.
+func (i *Inertia) renderSSR(r *http.Request, page *PageObject) (RenderedPage, error) {
+	t1 := time.Now()
+	engine, err := i.getSSREngine(r.Context())
+	if err != nil {
+		return RenderedPage{}, err
 	}
 
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	return i.rootTemplate.Execute(w, view)
+	ctx := r.Context()
+	if i.ssrTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.ssrTimeout)
+		defer cancel()
+	}
+
+	renderedPage, err := engine.Render(ctx, *page)
+	if err != nil {
+		return RenderedPage{}, err
+	}
+	i.logger.LogAttrs(
+		r.Context(), slog.LevelInfo,
+		"ssr engine rendered page",
+		slog.String("engine", engine.Name()),
+		slog.String("dur", time.Since(t1).String()),
+	)
+
+	return renderedPage, nil
 }
 
 // PageObject is the JSON structure sent to the Inertia client.
@@ -467,6 +996,10 @@ type scrollPropMetadata struct {
 type renderConfig struct {
 	encryptHistory *bool
 	clearHistory   *bool
+	noSSRCache     bool
+	ssrCacheKey    string
+	url            string
+	status         int
 }
 
 // RenderOption configures the behavior of a single Render call
@@ -486,11 +1019,43 @@ func WithClearHistory(clear bool) RenderOption {
 	}
 }
 
+// WithoutSSRCache bypasses the SSR cache for this render, e.g. for pages
+// with per-user content that should not take up cache space.
+func WithoutSSRCache() RenderOption {
+	return func(config *renderConfig) {
+		config.noSSRCache = true
+	}
+}
+
+// WithSSRCacheKey caches this render under key instead of a hash of the
+// page object. Every render with the same key is served the same HTML until
+// the entry expires or is invalidated with Inertia.InvalidateSSRCacheKey.
+func WithSSRCacheKey(key string) RenderOption {
+	return func(config *renderConfig) {
+		config.ssrCacheKey = key
+	}
+}
+
+// WithURL sets the page's URL instead of the request URI, e.g. to add the
+// path prefix stripped by a reverse proxy. The client shows this URL in the
+// address bar, so it should include the query string.
+func WithURL(url string) RenderOption {
+	return func(config *renderConfig) {
+		config.url = url
+	}
+}
+
 func (i *Inertia) Render(w http.ResponseWriter, r *http.Request, component string, props Props, options ...RenderOption) error {
 	if props == nil {
 		props = Props{}
 	}
 
+	if i.pages != nil {
+		if err := i.pages.check(component); err != nil {
+			return err
+		}
+	}
+
 	config := &renderConfig{}
 	for _, opt := range options {
 		opt(config)
@@ -544,9 +1109,14 @@ func (i *Inertia) Render(w http.ResponseWriter, r *http.Request, component strin
 		p.finalProps["errors"] = json.RawMessage("{}")
 	}
 
+	url := config.url
+	if url == "" {
+		url = r.URL.RequestURI()
+	}
+
 	pageObject := &PageObject{
 		Component:      component,
-		URL:            r.URL.Path,
+		URL:            url,
 		Props:          p.finalProps,
 		Version:        i.version,
 		EncryptHistory: config.encryptHistory != nil && *config.encryptHistory,
@@ -562,10 +1132,25 @@ func (i *Inertia) Render(w http.ResponseWriter, r *http.Request, component strin
 	}
 
 	if headers.IsInertia {
-		return i.renderJSON(w, r, pageObject)
+		return i.renderJSON(w, r, pageObject, config)
 	}
 
-	return i.renderHTML(w, r, pageObject)
+	return i.renderHTML(w, r, pageObject, config)
+}
+
+// WithStatus sets the response status instead of 200 OK, e.g. for an error
+// page. The Inertia client still renders the page for Inertia requests.
+func WithStatus(status int) RenderOption {
+	return func(config *renderConfig) {
+		config.status = status
+	}
+}
+
+// writeStatus writes the status set with WithStatus, if any.
+func (config *renderConfig) writeStatus(w http.ResponseWriter) {
+	if config.status != 0 {
+		w.WriteHeader(config.status)
+	}
 }
 
 // Redirect performs a server-side redirect.
@@ -3,8 +3,11 @@ package inertia_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -371,6 +374,60 @@ func TestRender_EncryptHistoryOption(t *testing.T) {
 	}
 }
 
+func TestRender_URL(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		target      string
+		options     []inertia.RenderOption
+		expectedURL string
+	}{
+		{
+			name:        "path only",
+			target:      "/users",
+			expectedURL: "/users",
+		},
+		{
+			name:        "keeps query string",
+			target:      "/users?page=2&sort=name",
+			expectedURL: "/users?page=2&sort=name",
+		},
+		{
+			name:        "keeps escaped path",
+			target:      "/files/a%2Fb?q=x%20y",
+			expectedURL: "/files/a%2Fb?q=x%20y",
+		},
+		{
+			name:        "WithURL overrides request URI",
+			target:      "/users?page=2",
+			options:     []inertia.RenderOption{inertia.WithURL("/admin/users?page=2")},
+			expectedURL: "/admin/users?page=2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.Header.Set(inertia.XInertia, "true")
+			w := httptest.NewRecorder()
+
+			err := i.Render(w, req, "TestComponent", nil, tt.options...)
+			require.NoError(t, err)
+
+			var resp inertia.PageObject
+			err = json.NewDecoder(w.Body).Decode(&resp)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedURL, resp.URL)
+		})
+	}
+}
+
 func TestRender_ClearHistoryOption(t *testing.T) {
 	bundler, err := vite.New(nil, vite.WithDevMode(true))
 	require.NoError(t, err)
@@ -994,3 +1051,260 @@ func TestRender_ScrollProp(t *testing.T) {
 		assert.NotContains(t, resp.Props, "foo")
 	})
 }
+
+func TestRender_PropConcurrency(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	t.Run("resolves props in parallel", func(t *testing.T) {
+		i, err := inertia.New(bundler, inertia.WithPropConcurrency(0))
+		require.NoError(t, err)
+
+		// Each resolver waits until all of them are running, which can only
+		// happen if they are resolved concurrently.
+		var started sync.WaitGroup
+		started.Add(3)
+		resolver := func(value string) inertia.PropFunc {
+			return func(ctx context.Context) (any, error) {
+				started.Done()
+				started.Wait()
+				return value, nil
+			}
+		}
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+
+		err = i.Render(w, req, "TestComponent", inertia.Props{
+			"a": inertia.Lazy(resolver("a")),
+			"b": inertia.Lazy(resolver("b")),
+			"c": inertia.Lazy(resolver("c")),
+		})
+		require.NoError(t, err)
+
+		var resp inertia.PageObject
+		err = json.NewDecoder(w.Body).Decode(&resp)
+		require.NoError(t, err)
+
+		assert.Equal(t, "a", resp.Props["a"])
+		assert.Equal(t, "b", resp.Props["b"])
+		assert.Equal(t, "c", resp.Props["c"])
+	})
+
+	t.Run("respects the concurrency limit", func(t *testing.T) {
+		i, err := inertia.New(bundler, inertia.WithPropConcurrency(2))
+		require.NoError(t, err)
+
+		var running, maxRunning atomic.Int32
+		resolver := func(ctx context.Context) (any, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return "ok", nil
+		}
+
+		props := inertia.Props{}
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			props[key] = inertia.Lazy(resolver)
+		}
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+
+		err = i.Render(w, req, "TestComponent", props)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	})
+
+	t.Run("first error cancels the other resolvers", func(t *testing.T) {
+		i, err := inertia.New(bundler, inertia.WithPropConcurrency(0))
+		require.NoError(t, err)
+
+		errBoom := errors.New("boom")
+		cancelled := make(chan error, 1)
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+
+		err = i.Render(w, req, "TestComponent", inertia.Props{
+			"fails": inertia.Lazy(func(ctx context.Context) (any, error) {
+				return nil, errBoom
+			}),
+			"slow": inertia.Lazy(func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				cancelled <- context.Cause(ctx)
+				return nil, ctx.Err()
+			}),
+		})
+		require.ErrorIs(t, err, errBoom)
+		assert.ErrorIs(t, <-cancelled, errBoom)
+	})
+
+	t.Run("metadata order is deterministic", func(t *testing.T) {
+		i, err := inertia.New(bundler, inertia.WithPropConcurrency(0))
+		require.NoError(t, err)
+
+		resolver := func(ctx context.Context) (any, error) { return []string{}, nil }
+		props := inertia.Props{
+			"posts":    inertia.Merge(resolver),
+			"comments": inertia.Merge(resolver),
+			"users":    inertia.Merge(resolver),
+			"stats":    inertia.Deferred(resolver),
+			"charts":   inertia.Deferred(resolver),
+		}
+
+		for range 10 {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set(inertia.XInertia, "true")
+			w := httptest.NewRecorder()
+
+			err = i.Render(w, req, "TestComponent", props)
+			require.NoError(t, err)
+
+			var resp inertia.PageObject
+			err = json.NewDecoder(w.Body).Decode(&resp)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"comments", "posts", "users"}, resp.MergeProps)
+			assert.Equal(t, []string{"charts", "stats"}, resp.DeferredProps["default"])
+		}
+	})
+}
+
+func TestRender_NestedProps(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	var (
+		mu       sync.Mutex
+		resolved []string
+	)
+	lazy := func(name string) inertia.Prop {
+		return inertia.Lazy(func(ctx context.Context) (any, error) {
+			mu.Lock()
+			resolved = append(resolved, name)
+			mu.Unlock()
+			return name, nil
+		})
+	}
+
+	props := func() inertia.Props {
+		return inertia.Props{
+			"auth": inertia.Props{
+				"user":        lazy("user"),
+				"permissions": lazy("permissions"),
+			},
+			"stats": inertia.Props{
+				"revenue": lazy("revenue"),
+				"visits": inertia.Props{
+					"today": lazy("today"),
+				},
+				"forecast": inertia.Deferred(func(ctx context.Context) (any, error) { return "forecast", nil }),
+			},
+			"title": inertia.Value("Dashboard"),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		only     string
+		except   string
+		expected string
+		resolved []string
+		deferred map[string][]string
+	}{
+		{
+			name:     "Full Load",
+			expected: `{"auth":{"permissions":"permissions","user":"user"},"stats":{"revenue":"revenue","visits":{"today":"today"}},"title":"Dashboard"}`,
+			resolved: []string{"permissions", "user", "revenue", "today"},
+			deferred: map[string][]string{"default": {"stats.forecast"}},
+		},
+		{
+			name:     "Only Nested Path",
+			only:     "auth.user",
+			expected: `{"auth":{"user":"user"}}`,
+			resolved: []string{"user"},
+		},
+		{
+			name:     "Only Group",
+			only:     "stats",
+			expected: `{"stats":{"forecast":"forecast","revenue":"revenue","visits":{"today":"today"}}}`,
+			resolved: []string{"revenue", "today"},
+		},
+		{
+			name:     "Only Deep Path And Top Level",
+			only:     "stats.visits.today,title",
+			expected: `{"stats":{"visits":{"today":"today"}},"title":"Dashboard"}`,
+			resolved: []string{"today"},
+		},
+		{
+			name:     "Only Deferred Nested Prop",
+			only:     "stats.forecast",
+			expected: `{"stats":{"forecast":"forecast"}}`,
+			resolved: nil,
+		},
+		{
+			name:     "Except Nested Path",
+			except:   "auth.permissions,stats",
+			expected: `{"auth":{"user":"user"},"title":"Dashboard"}`,
+			resolved: []string{"user"},
+		},
+		{
+			name:     "Only Group Except Child",
+			only:     "stats",
+			except:   "stats.visits",
+			expected: `{"stats":{"forecast":"forecast","revenue":"revenue"}}`,
+			resolved: []string{"revenue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved = nil
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set(inertia.XInertia, "true")
+			if tt.only != "" || tt.except != "" {
+				req.Header.Set(inertia.XInertiaPartialComponent, "Dashboard")
+			}
+			if tt.only != "" {
+				req.Header.Set(inertia.XInertiaPartialData, tt.only)
+			}
+			if tt.except != "" {
+				req.Header.Set(inertia.XInertiaPartialExcept, tt.except)
+			}
+			w := httptest.NewRecorder()
+
+			err := i.Render(w, req, "Dashboard", props())
+			require.NoError(t, err)
+
+			var resp inertia.PageObject
+			err = json.NewDecoder(w.Body).Decode(&resp)
+			require.NoError(t, err)
+
+			delete(resp.Props, "errors")
+			actual, err := json.Marshal(resp.Props)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, tt.expected, string(actual))
+			assert.ElementsMatch(t, tt.resolved, resolved)
+			if tt.deferred == nil {
+				assert.Empty(t, resp.DeferredProps)
+			} else {
+				assert.Equal(t, tt.deferred, resp.DeferredProps)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// Package propstruct parses the `inertia` struct tags used by typed props, so
+// rendering and type generation agree on how fields map to props.
+package propstruct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Field is a struct field that becomes a prop.
+type Field struct {
+	GoName string
+	Index  []int
+	Type   reflect.Type
+
+	Name    string
+	Kind    string // empty for Prop fields
+	Group   string
+	Deep    bool
+	MatchOn []string
+
+	// Prop is set if the field's type implements the Prop interface.
+	Prop bool
+	// Resolver is set if the field is a func(context.Context) (T, error).
+	Resolver bool
+}
+
+var (
+	cache       sync.Map // reflect.Type -> []Field or error
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
+)
+
+// Fields returns the prop fields of struct type t. propType is the Prop
+// interface; fields implementing it are passed through unchanged.
+func Fields(t reflect.Type, propType reflect.Type) ([]Field, error) {
+	if cached, ok := cache.Load(t); ok {
+		if err, ok := cached.(error); ok {
+			return nil, err
+		}
+		return cached.([]Field), nil
+	}
+
+	fields, err := parseFields(t, nil, propType)
+	if err != nil {
+		cache.Store(t, err)
+		return nil, err
+	}
+
+	cache.Store(t, fields)
+	return fields, nil
+}
+
+// IsResolver reports whether t is func(context.Context) (T, error).
+func IsResolver(t reflect.Type) bool {
+	return t.Kind() == reflect.Func &&
+		t.NumIn() == 1 && t.In(0) == contextType &&
+		t.NumOut() == 2 && t.Out(1) == errorType
+}
+
+// FieldByIndex is reflect.Value.FieldByIndex, reporting false instead of
+// panicking when it would go through a nil embedded pointer.
+func FieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func parseFields(t reflect.Type, index []int, propType reflect.Type) ([]Field, error) {
+	var fields []Field
+	seen := map[string]string{}
+
+	add := func(field Field) error {
+		if other, ok := seen[field.Name]; ok {
+			return fmt.Errorf("inertia: %s: fields %s and %s are both named %q", t, other, field.GoName, field.Name)
+		}
+		seen[field.Name] = field.GoName
+		fields = append(fields, field)
+		return nil
+	}
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup("inertia")
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		if sf.Anonymous && !hasTag {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded, err := parseFields(ft, fieldIndex, propType)
+				if err != nil {
+					return nil, err
+				}
+				for _, field := range embedded {
+					if err := add(field); err != nil {
+						return nil, err
+					}
+				}
+				continue
+			}
+		}
+
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+		if !hasTag && sf.Tag.Get("json") == "-" {
+			// Left out of JSON, so left out of the props too, unless an
+			// inertia tag asks for it.
+			continue
+		}
+
+		field, err := parseField(sf, tag, propType)
+		if err != nil {
+			return nil, fmt.Errorf("inertia: %s.%s: %w", t, sf.Name, err)
+		}
+		field.Index = fieldIndex
+
+		if err := add(field); err != nil {
+			return nil, err
+		}
+	}
+
+	return fields, nil
+}
+
+func parseField(sf reflect.StructField, tag string, propType reflect.Type) (Field, error) {
+	field := Field{
+		GoName:   sf.Name,
+		Type:     sf.Type,
+		Prop:     sf.Type.Implements(propType),
+		Resolver: IsResolver(sf.Type),
+	}
+
+	parts := strings.Split(tag, ",")
+	field.Name = parts[0]
+	if field.Name == "" {
+		field.Name = jsonName(sf)
+	}
+
+	if len(parts) > 1 {
+		field.Kind = parts[1]
+	}
+
+	for _, opt := range parts[min(2, len(parts)):] {
+		key, value, _ := strings.Cut(opt, "=")
+		switch {
+		case key == "group" && field.Kind == "deferred":
+			field.Group = value
+		case key == "deep" && field.Kind == "merge":
+			field.Deep = true
+		case key == "matchOn" && field.Kind == "merge":
+			field.MatchOn = append(field.MatchOn, value)
+		default:
+			return field, fmt.Errorf("unknown option %q for kind %q", opt, field.Kind)
+		}
+	}
+
+	switch field.Kind {
+	case "":
+		if field.Resolver {
+			field.Kind = "lazy"
+		} else if !field.Prop {
+			field.Kind = "value"
+		}
+	case "value", "always":
+		if field.Resolver {
+			return field, fmt.Errorf("kind %q cannot hold a resolver", field.Kind)
+		}
+	case "lazy", "optional", "deferred", "once", "merge":
+	default:
+		return field, fmt.Errorf("unknown kind %q", field.Kind)
+	}
+
+	if field.Prop && field.Kind != "" {
+		return field, fmt.Errorf("%s is a Prop and cannot have kind %q", sf.Type, field.Kind)
+	}
+
+	return field, nil
+}
+
+func jsonName(sf reflect.StructField) string {
+	if tag, ok := sf.Tag.Lookup("json"); ok {
+		if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
@@ -9,11 +9,25 @@ import (
 // - Asset versioning (409 Conflict on version mismatch for GET requests)
 // - Managing shared and flash props via pooled inertiaContext
 // - CSRF protection (if enabled)
+// - Panic recovery (if enabled)
 func (i *Inertia) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ic := inertiaContextPool.Get()
 		defer inertiaContextPool.Put(ic)
 
+		if i.recovery {
+			rw := NewTrackingWriter(w)
+			w = rw
+
+			// Deferred after the pool Put, so this runs first and the error
+			// page still has the request's shared props.
+			defer func() {
+				if v := recover(); v != nil {
+					i.recoverPanic(rw, r, v)
+				}
+			}()
+		}
+
 		if flashData, _ := i.session.Get(w, r); flashData != nil {
 			ic.flash = flashData
 		}
@@ -0,0 +1,161 @@
+package inertia
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownPage is returned by Render for a component that is not in the
+// page list set with WithPages.
+var ErrUnknownPage = errors.New("inertia: unknown page component")
+
+// PageSource lists the page components an app can render, such as
+// "users/Index".
+type PageSource func() ([]string, error)
+
+// DefaultPageExtensions are the file extensions PagesFromFS lists when none
+// are given.
+var DefaultPageExtensions = []string{".tsx", ".jsx", ".ts", ".js", ".vue", ".svelte"}
+
+// PagesFromFS lists the files under dir in fsys as page components, named by
+// their path relative to dir without the extension, so
+// "assets/ts/pages/users/Index.tsx" is "users/Index". Only files with one of
+// exts are listed, or with one of DefaultPageExtensions if exts is empty.
+func PagesFromFS(fsys fs.FS, dir string, exts ...string) PageSource {
+	if len(exts) == 0 {
+		exts = DefaultPageExtensions
+	}
+
+	return func() ([]string, error) {
+		var pages []string
+		err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if name, ok := pageName(dir, p, exts); ok {
+				pages = append(pages, name)
+			}
+			return nil
+		})
+		return pages, err
+	}
+}
+
+// PagesFromDir is PagesFromFS for a directory on disk.
+func PagesFromDir(dir string, exts ...string) PageSource {
+	return PagesFromFS(os.DirFS(dir), ".", exts...)
+}
+
+// PagesFromList is a fixed list of page components.
+func PagesFromList(pages ...string) PageSource {
+	return func() ([]string, error) {
+		return pages, nil
+	}
+}
+
+// pageName returns the component name of file p under dir, if it has one of
+// exts.
+func pageName(dir, p string, exts []string) (string, bool) {
+	ext := path.Ext(p)
+	if !slices.Contains(exts, ext) {
+		return "", false
+	}
+
+	name := strings.TrimSuffix(p, ext)
+	if dir != "." {
+		rest, ok := strings.CutPrefix(name, dir+"/")
+		if !ok {
+			return "", false
+		}
+		name = rest
+	}
+	return name, true
+}
+
+// pageRegistry holds the page components loaded from a PageSource.
+type pageRegistry struct {
+	source PageSource
+	reload bool // reload on a miss, for pages added in dev
+
+	mu    sync.RWMutex
+	pages map[string]struct{}
+}
+
+func newPageRegistry(source PageSource, reload bool) *pageRegistry {
+	return &pageRegistry{source: source, reload: reload}
+}
+
+func (p *pageRegistry) load() error {
+	pages, err := p.source()
+	if err != nil {
+		return err
+	}
+
+	set := make(map[string]struct{}, len(pages))
+	for _, page := range pages {
+		set[page] = struct{}{}
+	}
+
+	p.mu.Lock()
+	p.pages = set
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (p *pageRegistry) has(component string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	_, ok := p.pages[component]
+	return ok
+}
+
+// check returns ErrUnknownPage if component is not a page. In dev the list
+// is reloaded first, in case the page was just added.
+func (p *pageRegistry) check(component string) error {
+	if p.has(component) {
+		return nil
+	}
+
+	if p.reload {
+		if err := p.load(); err != nil {
+			return fmt.Errorf("inertia: loading pages: %w", err)
+		}
+		if p.has(component) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w %q", ErrUnknownPage, component)
+}
+
+func (p *pageRegistry) list() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	pages := make([]string, 0, len(p.pages))
+	for page := range p.pages {
+		pages = append(pages, page)
+	}
+	slices.Sort(pages)
+	return pages
+}
+
+// Pages returns the page components set with WithPages, in sorted order, or
+// nil if there is no page list.
+func (i *Inertia) Pages() []string {
+	if i.pages == nil {
+		return nil
+	}
+	return i.pages.list()
+}
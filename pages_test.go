@@ -0,0 +1,130 @@
+package inertia_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+func TestPagesFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"assets/ts/pages/Home.tsx":          {},
+		"assets/ts/pages/users/Index.tsx":   {},
+		"assets/ts/pages/users/Show.vue":    {},
+		"assets/ts/pages/users/helpers.css": {},
+		"assets/ts/app.tsx":                 {},
+	}
+
+	pages, err := inertia.PagesFromFS(fsys, "assets/ts/pages")()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Home", "users/Index", "users/Show"}, pages)
+
+	pages, err = inertia.PagesFromFS(fsys, "assets/ts/pages", ".vue")()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"users/Show"}, pages)
+
+	_, err = inertia.PagesFromFS(fsys, "missing")()
+	assert.Error(t, err)
+}
+
+func TestWithPages(t *testing.T) {
+	t.Run("Render rejects unknown pages", func(t *testing.T) {
+		bundler, err := vite.New(nil, vite.WithDevMode(true))
+		require.NoError(t, err)
+
+		i, err := inertia.New(bundler, inertia.WithPages(inertia.PagesFromList("users/Index", "Home")))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Home", "users/Index"}, i.Pages())
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+
+		err = i.Render(httptest.NewRecorder(), req, "Home", nil)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		err = i.Render(w, req, "users/index", nil)
+		assert.ErrorIs(t, err, inertia.ErrUnknownPage)
+		assert.ErrorContains(t, err, `"users/index"`)
+		assert.Zero(t, w.Body.Len())
+	})
+
+	t.Run("dev reloads pages on a miss", func(t *testing.T) {
+		bundler, err := vite.New(nil, vite.WithDevMode(true))
+		require.NoError(t, err)
+
+		pages := []string{"Home"}
+		i, err := inertia.New(bundler, inertia.WithPages(func() ([]string, error) {
+			return pages, nil
+		}))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+
+		pages = []string{"Home", "About"}
+		err = i.Render(httptest.NewRecorder(), req, "About", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"About", "Home"}, i.Pages())
+	})
+
+	t.Run("dev tolerates a failing source", func(t *testing.T) {
+		bundler, err := vite.New(nil, vite.WithDevMode(true))
+		require.NoError(t, err)
+
+		_, err = inertia.New(bundler, inertia.WithPages(func() ([]string, error) {
+			return nil, errors.New("boom")
+		}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("production fails fast", func(t *testing.T) {
+		bundler, err := vite.New(nil)
+		require.NoError(t, err)
+
+		_, err = inertia.New(bundler, inertia.WithPages(func() ([]string, error) {
+			return nil, errors.New("boom")
+		}))
+		assert.ErrorContains(t, err, "boom")
+
+		_, err = inertia.New(bundler, inertia.WithPages(inertia.PagesFromList()))
+		assert.ErrorContains(t, err, "no pages found")
+	})
+
+	t.Run("production does not reload", func(t *testing.T) {
+		bundler, err := vite.New(nil)
+		require.NoError(t, err)
+
+		calls := 0
+		i, err := inertia.New(bundler, inertia.WithPages(func() ([]string, error) {
+			calls++
+			return []string{"Home"}, nil
+		}))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+
+		err = i.Render(httptest.NewRecorder(), req, "Missing", nil)
+		assert.ErrorIs(t, err, inertia.ErrUnknownPage)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestPages_WithoutRegistry(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	assert.Nil(t, i.Pages())
+}
@@ -2,7 +2,7 @@ package inertia
 
 import (
 	"context"
-	"slices"
+	"strings"
 )
 
 // PropFunc is the resolver function signature for lazy-evaluated props.
@@ -11,7 +11,8 @@ type PropFunc func(ctx context.Context) (any, error)
 // Prop is the interface that all prop types must implement.
 type Prop interface {
 	// shouldInclude determines if the prop should be included in the response
-	// based on the request headers and partial reload state.
+	// based on the request headers and partial reload state. key is the
+	// prop's dot-separated path, e.g. "auth.user" for a nested prop.
 	shouldInclude(key string, headers *inertiaHeaders) bool
 
 	// resolve executes the prop's resolver or returns its static value.
@@ -23,8 +24,49 @@ type Prop interface {
 }
 
 // Props is a map of prop names to values.
+//
+// Props is itself a Prop, so props can be nested. Partial reloads can then
+// request or exclude nested props by dot-separated path, e.g. "auth.user",
+// and only the requested subtrees are resolved:
+//
+//	inertia.Props{
+//	    "auth": inertia.Props{
+//	        "user":        inertia.Lazy(loadUser),
+//	        "permissions": inertia.Lazy(loadPermissions),
+//	    },
+//	}
 type Props map[string]Prop
 
+// shouldInclude includes a nested group on full load, and on partial reload
+// if it is requested, nested under a requested path, or contains a
+// requested path. Its children are then filtered by their own paths.
+func (p Props) shouldInclude(key string, headers *inertiaHeaders) bool {
+	if headers.IsPartial {
+		includedByData := len(headers.PartialData) == 0 ||
+			pathCovered(headers.PartialData, key) ||
+			pathHasDescendant(headers.PartialData, key)
+
+		return includedByData && !pathCovered(headers.PartialExcept, key)
+	}
+	return true
+}
+
+// resolve resolves every nested prop. Rendering walks nested props itself so
+// it can filter them; this is only used when a Props is resolved directly.
+func (p Props) resolve(ctx context.Context) (any, error) {
+	values := make(map[string]any, len(p))
+	for key, prop := range p {
+		value, err := prop.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+func (p Props) modifyProcessedProps(key string, headers *inertiaHeaders, pp *processedProps) {}
+
 // oncePropData is the JSON structure for once props in response.
 type oncePropData struct {
 	Prop      string `json:"prop"`
@@ -34,20 +76,39 @@ type oncePropData struct {
 
 // defaultShouldInclude is the shared logic for default/lazy/value/scroll props.
 // It includes the prop on full load, and on partial reload only if requested
-// (or if no specific props are requested) and not excluded.
+// (or if no specific props are requested) and not excluded. Requesting or
+// excluding a path also covers every prop nested under it.
 func defaultShouldInclude(key string, headers *inertiaHeaders) bool {
 	if headers.IsPartial {
-		includedByData := len(headers.PartialData) == 0
-		if len(headers.PartialData) > 0 {
-			includedByData = slices.Contains(headers.PartialData, key)
-		}
+		includedByData := len(headers.PartialData) == 0 || pathCovered(headers.PartialData, key)
+		return includedByData && !pathCovered(headers.PartialExcept, key)
+	}
+	return true
+}
+
+// explicitlyRequested reports whether a partial reload asked for the prop,
+// directly or through one of its parents. Optional and deferred props are
+// only included then.
+func explicitlyRequested(key string, headers *inertiaHeaders) bool {
+	return headers.IsPartial && pathCovered(headers.PartialData, key)
+}
 
-		excluded := false
-		if len(headers.PartialExcept) > 0 {
-			excluded = slices.Contains(headers.PartialExcept, key)
+// pathCovered reports whether key is one of paths or nested under one of them.
+func pathCovered(paths []string, key string) bool {
+	for _, path := range paths {
+		if key == path || strings.HasPrefix(key, path+".") {
+			return true
 		}
+	}
+	return false
+}
 
-		return includedByData && !excluded
+// pathHasDescendant reports whether one of paths is nested under key.
+func pathHasDescendant(paths []string, key string) bool {
+	for _, path := range paths {
+		if strings.HasPrefix(path, key+".") {
+			return true
+		}
 	}
-	return true
+	return false
 }
@@ -1,9 +1,6 @@
 package inertia
 
-import (
-	"context"
-	"slices"
-)
+import "context"
 
 // deferredProp is excluded on initial load and fetched later via partial reload.
 type deferredProp struct {
@@ -25,10 +22,7 @@ func DeferredGroup(group string, resolver PropFunc) Prop {
 
 func (p deferredProp) shouldInclude(key string, headers *inertiaHeaders) bool {
 	// Only include if explicitly requested in partial reload
-	if headers.IsPartial && len(headers.PartialData) > 0 {
-		return slices.Contains(headers.PartialData, key)
-	}
-	return false
+	return explicitlyRequested(key, headers)
 }
 
 func (p deferredProp) resolve(ctx context.Context) (any, error) {
@@ -1,9 +1,6 @@
 package inertia
 
-import (
-	"context"
-	"slices"
-)
+import "context"
 
 // optionalProp is excluded by default and only included when explicitly requested.
 type optionalProp struct {
@@ -18,10 +15,7 @@ func Optional(resolver PropFunc) Prop {
 
 func (p optionalProp) shouldInclude(key string, headers *inertiaHeaders) bool {
 	// Only include if explicitly requested in a partial reload
-	if headers.IsPartial && len(headers.PartialData) > 0 {
-		return slices.Contains(headers.PartialData, key)
-	}
-	return false
+	return explicitlyRequested(key, headers)
 }
 
 func (p optionalProp) resolve(ctx context.Context) (any, error) {
@@ -0,0 +1,257 @@
+package qjs
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/fastschema/qjs"
+)
+
+//go:embed polyfills/*.js
+var polyfills embed.FS
+
+// HostFunc is a Go function exposed to JavaScript as a global. Arguments are
+// converted to Go values (strings, float64s, bools, []any, map[string]any)
+// and the result is converted back. A returned error is thrown in
+// JavaScript.
+type HostFunc func(args []any) (any, error)
+
+type bootstrapScript struct {
+	name   string
+	source string
+}
+
+type hostFunc struct {
+	name string
+	fn   HostFunc
+}
+
+// WithBootstrapScript evaluates source in every runtime before the SSR
+// bundle, in the order the scripts are registered. Scripts run as classic
+// scripts, so they can define globals the bundle relies on.
+func WithBootstrapScript(name, source string) Option {
+	return func(config *config) error {
+		config.scripts = append(config.scripts, bootstrapScript{name: name, source: source})
+		return nil
+	}
+}
+
+// WithHostFunc defines a global JavaScript function named name that calls fn.
+// Host functions are defined before any bootstrap script runs.
+func WithHostFunc(name string, fn HostFunc) Option {
+	return func(config *config) error {
+		if name == "" {
+			return errors.New("qjs: host function name is required")
+		}
+		config.hostFuncs = append(config.hostFuncs, hostFunc{name: name, fn: fn})
+		return nil
+	}
+}
+
+// WithWebGlobals installs implementations of the web globals SSR bundles
+// commonly expect: URL, URLSearchParams and structuredClone, plus a console
+// whose output goes to the logger set with WithLogger.
+// queueMicrotask and setTimeout are provided by QuickJS itself.
+func WithWebGlobals() Option {
+	return func(config *config) error {
+		config.webGlobals = true
+		return nil
+	}
+}
+
+// bootstrap prepares a fresh runtime for the SSR bundle: it defines the host
+// functions and then evaluates the polyfills and bootstrap scripts.
+func (q *Engine) bootstrap(ctx *qjs.Context) error {
+	scripts := []bootstrapScript{mustPolyfill("text_encoding.js")}
+
+	if q.config.webGlobals {
+		ctx.SetFunc("__inertigo_parse_url", HostFunc(parseURL).function())
+		ctx.SetFunc("__inertigo_console", HostFunc(q.console).function())
+		scripts = append(scripts,
+			mustPolyfill("url.js"),
+			mustPolyfill("structured_clone.js"),
+			mustPolyfill("console.js"),
+		)
+	}
+
+	for _, h := range q.config.hostFuncs {
+		ctx.SetFunc(h.name, h.fn.function())
+	}
+
+	scripts = append(scripts, q.config.scripts...)
+
+	for _, script := range scripts {
+		val, err := ctx.Eval(script.name, qjs.Code(script.source))
+		if err != nil {
+			return fmt.Errorf("qjs: bootstrap script %s: %w", script.name, err)
+		}
+		val.Free()
+	}
+
+	return nil
+}
+
+func (fn HostFunc) function() qjs.Function {
+	return func(this *qjs.This) (*qjs.Value, error) {
+		args := make([]any, len(this.Args()))
+		for i, arg := range this.Args() {
+			v, err := qjs.ToGoValue[any](arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+
+		res, err := fn(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return qjs.ToJsValue(this.Context(), res)
+	}
+}
+
+func mustPolyfill(name string) bootstrapScript {
+	source, err := polyfills.ReadFile("polyfills/" + name)
+	if err != nil {
+		panic(err)
+	}
+	return bootstrapScript{name: name, source: string(source)}
+}
+
+var consoleLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// console backs the console polyfill: console(level, message).
+func (q *Engine) console(args []any) (any, error) {
+	level, message := stringArg(args, 0), stringArg(args, 1)
+
+	q.config.logger.LogAttrs(context.Background(), consoleLevels[level], "qjs console",
+		slog.String("msg", message),
+	)
+
+	return nil, nil
+}
+
+// parseURL backs the URL polyfill: parseURL(url, base) returns the URL's
+// components as WHATWG URL getters would report them.
+func parseURL(args []any) (any, error) {
+	raw, base := stringArg(args, 0), stringArg(args, 1)
+
+	var u *url.URL
+	var err error
+	if base != "" {
+		var b *url.URL
+		b, err = url.Parse(base)
+		if err == nil && b.Scheme == "" {
+			err = errors.New("base is not absolute")
+		}
+		if err == nil {
+			u, err = b.Parse(raw)
+		}
+	} else {
+		u, err = url.Parse(raw)
+		if err == nil && u.Scheme == "" {
+			err = errors.New("url is not absolute")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("TypeError: Invalid URL: %q", raw)
+	}
+
+	protocol := u.Scheme + ":"
+	hostname := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if defaultPorts[u.Scheme] == port {
+		port = ""
+	}
+
+	host := hostname
+	if port != "" {
+		host += ":" + port
+	}
+
+	pathname := u.EscapedPath()
+	if u.Opaque != "" {
+		pathname = u.Opaque
+	} else if pathname == "" && (u.Host != "" || defaultPorts[u.Scheme] != "") {
+		pathname = "/"
+	}
+
+	search := ""
+	if u.RawQuery != "" {
+		search = "?" + u.RawQuery
+	}
+
+	hash := ""
+	if u.Fragment != "" {
+		hash = "#" + u.EscapedFragment()
+	}
+
+	username, password := "", ""
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+	}
+
+	origin := "null"
+	if _, ok := defaultPorts[u.Scheme]; ok && u.Scheme != "file" {
+		origin = protocol + "//" + host
+	}
+
+	auth := ""
+	if username != "" {
+		auth = username
+		if password != "" {
+			auth += ":" + password
+		}
+		auth += "@"
+	}
+
+	href := protocol
+	if u.Host != "" || u.Scheme == "file" {
+		href += "//" + auth + host
+	}
+	href += pathname + search + hash
+
+	return map[string]string{
+		"href":     href,
+		"origin":   origin,
+		"protocol": protocol,
+		"username": username,
+		"password": password,
+		"host":     host,
+		"hostname": hostname,
+		"port":     port,
+		"pathname": pathname,
+		"search":   search,
+		"hash":     hash,
+	}, nil
+}
+
+// defaultPorts lists the WHATWG special schemes and their default ports.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+	"file":  "",
+}
+
+func stringArg(args []any, i int) string {
+	if i >= len(args) {
+		return ""
+	}
+	s, _ := args[i].(string)
+	return s
+}
@@ -0,0 +1,28 @@
+// Routes console output to the Go logger through __inertigo_console.
+(function (g) {
+  "use strict";
+
+  const log = g.__inertigo_console;
+
+  const format = (value) => {
+    if (typeof value === "string") return value;
+    if (value instanceof Error) return value.stack || String(value);
+    try {
+      const json = JSON.stringify(value);
+      return json === undefined ? String(value) : json;
+    } catch {
+      return String(value);
+    }
+  };
+
+  const writer = (level) => (...args) => log(level, args.map(format).join(" "));
+
+  const console = g.console || {};
+  console.debug = writer("debug");
+  console.log = writer("info");
+  console.info = writer("info");
+  console.warn = writer("warn");
+  console.error = writer("error");
+  console.trace = writer("debug");
+  g.console = console;
+})(globalThis);
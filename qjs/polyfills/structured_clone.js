@@ -0,0 +1,62 @@
+// structuredClone for the value types an SSR bundle is likely to pass around.
+(function (g) {
+  "use strict";
+
+  if (typeof g.structuredClone === "function") return;
+
+  const dataCloneError = (message) => {
+    const err = new Error(message);
+    err.name = "DataCloneError";
+    return err;
+  };
+
+  const clone = (value, seen) => {
+    if (value === null || typeof value !== "object") {
+      if (typeof value === "function" || typeof value === "symbol") {
+        throw dataCloneError(String(value) + " could not be cloned.");
+      }
+      return value;
+    }
+
+    if (seen.has(value)) return seen.get(value);
+
+    let out;
+    if (value instanceof Date) {
+      out = new Date(value.getTime());
+    } else if (value instanceof RegExp) {
+      out = new RegExp(value.source, value.flags);
+    } else if (value instanceof ArrayBuffer) {
+      out = value.slice(0);
+    } else if (ArrayBuffer.isView(value)) {
+      out = new value.constructor(clone(value.buffer, seen), value.byteOffset, value.length);
+    } else if (value instanceof Map) {
+      out = new Map();
+      seen.set(value, out);
+      value.forEach((v, k) => out.set(clone(k, seen), clone(v, seen)));
+      return out;
+    } else if (value instanceof Set) {
+      out = new Set();
+      seen.set(value, out);
+      value.forEach((v) => out.add(clone(v, seen)));
+      return out;
+    } else if (value instanceof Error) {
+      out = new value.constructor(value.message);
+      out.stack = value.stack;
+    } else if (Array.isArray(value)) {
+      out = new Array(value.length);
+      seen.set(value, out);
+      for (let i = 0; i < value.length; i++) out[i] = clone(value[i], seen);
+      return out;
+    } else {
+      out = {};
+      seen.set(value, out);
+      for (const key of Object.keys(value)) out[key] = clone(value[key], seen);
+      return out;
+    }
+
+    seen.set(value, out);
+    return out;
+  };
+
+  g.structuredClone = (value) => clone(value, new Map());
+})(globalThis);
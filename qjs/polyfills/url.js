@@ -0,0 +1,206 @@
+// URL and URLSearchParams backed by Go's net/url through __inertigo_parse_url.
+(function (g) {
+  "use strict";
+
+  if (typeof g.URL === "function") return;
+
+  const parse = g.__inertigo_parse_url;
+
+  const encode = (s) =>
+    encodeURIComponent(s)
+      .replace(/[!'()~]/g, (c) => "%" + c.charCodeAt(0).toString(16).toUpperCase())
+      .replace(/%20/g, "+");
+
+  const decode = (s) => {
+    try {
+      return decodeURIComponent(s.replace(/\+/g, " "));
+    } catch {
+      return s;
+    }
+  };
+
+  class URLSearchParams {
+    #list = [];
+    #onUpdate = null;
+
+    constructor(init = "") {
+      if (init instanceof URLSearchParams) {
+        this.#list = init.#list.map(([k, v]) => [k, v]);
+      } else if (typeof init === "object" && init !== null) {
+        const entries = typeof init[Symbol.iterator] === "function" ? init : Object.entries(init);
+        for (const [k, v] of entries) this.#list.push([String(k), String(v)]);
+      } else {
+        this.#parse(String(init));
+      }
+    }
+
+    #parse(query) {
+      this.#list = [];
+      if (query.startsWith("?")) query = query.slice(1);
+      for (const part of query.split("&")) {
+        if (!part) continue;
+        const eq = part.indexOf("=");
+        const key = eq === -1 ? part : part.slice(0, eq);
+        const value = eq === -1 ? "" : part.slice(eq + 1);
+        this.#list.push([decode(key), decode(value)]);
+      }
+    }
+
+    #update() {
+      if (this.#onUpdate) this.#onUpdate(this.toString());
+    }
+
+    static _bind(params, query, onUpdate) {
+      params.#parse(query);
+      params.#onUpdate = onUpdate;
+    }
+
+    get size() {
+      return this.#list.length;
+    }
+
+    append(name, value) {
+      this.#list.push([String(name), String(value)]);
+      this.#update();
+    }
+
+    delete(name, value) {
+      name = String(name);
+      this.#list = this.#list.filter(([k, v]) => k !== name || (value !== undefined && v !== String(value)));
+      this.#update();
+    }
+
+    get(name) {
+      const entry = this.#list.find(([k]) => k === String(name));
+      return entry ? entry[1] : null;
+    }
+
+    getAll(name) {
+      return this.#list.filter(([k]) => k === String(name)).map(([, v]) => v);
+    }
+
+    has(name, value) {
+      return this.#list.some(([k, v]) => k === String(name) && (value === undefined || v === String(value)));
+    }
+
+    set(name, value) {
+      name = String(name);
+      const index = this.#list.findIndex(([k]) => k === name);
+      if (index === -1) {
+        this.#list.push([name, String(value)]);
+      } else {
+        this.#list[index][1] = String(value);
+        this.#list = this.#list.filter(([k], i) => k !== name || i <= index);
+      }
+      this.#update();
+    }
+
+    sort() {
+      this.#list.sort(([a], [b]) => (a < b ? -1 : a > b ? 1 : 0));
+      this.#update();
+    }
+
+    forEach(callback, thisArg) {
+      for (const [k, v] of this.#list) callback.call(thisArg, v, k, this);
+    }
+
+    *entries() {
+      for (const [k, v] of this.#list) yield [k, v];
+    }
+
+    *keys() {
+      for (const [k] of this.#list) yield k;
+    }
+
+    *values() {
+      for (const [, v] of this.#list) yield v;
+    }
+
+    [Symbol.iterator]() {
+      return this.entries();
+    }
+
+    toString() {
+      return this.#list.map(([k, v]) => encode(k) + "=" + encode(v)).join("&");
+    }
+  }
+
+  class URL {
+    #parts;
+    #searchParams = new URLSearchParams();
+
+    constructor(url, base) {
+      this.#set(parse(String(url), base === undefined ? "" : String(base)));
+    }
+
+    static canParse(url, base) {
+      try {
+        new URL(url, base);
+        return true;
+      } catch {
+        return false;
+      }
+    }
+
+    #set(parts) {
+      this.#parts = parts;
+      URLSearchParams._bind(this.#searchParams, parts.search, (query) => {
+        this.#parts = parse(this.#with({ search: query ? "?" + query : "" }), "");
+      });
+    }
+
+    // Rebuilds the href with some components replaced.
+    #with(changes) {
+      const p = { ...this.#parts, ...changes };
+      const auth = p.username ? p.username + (p.password ? ":" + p.password : "") + "@" : "";
+      const host = p.hostname + (p.port ? ":" + p.port : "");
+      const slashes = p.hostname || p.protocol === "file:" ? "//" : "";
+      return p.protocol + slashes + auth + host + p.pathname + p.search + p.hash;
+    }
+
+    #change(changes) {
+      this.#set(parse(this.#with(changes), ""));
+    }
+
+    get href() { return this.#parts.href; }
+    set href(value) { this.#set(parse(String(value), "")); }
+    get origin() { return this.#parts.origin; }
+    get protocol() { return this.#parts.protocol; }
+    set protocol(value) { this.#change({ protocol: String(value).replace(/:?$/, ":") }); }
+    get username() { return this.#parts.username; }
+    set username(value) { this.#change({ username: String(value) }); }
+    get password() { return this.#parts.password; }
+    set password(value) { this.#change({ password: String(value) }); }
+    get host() { return this.#parts.host; }
+    set host(value) {
+      const [hostname, port = ""] = String(value).split(":");
+      this.#change({ hostname, port });
+    }
+    get hostname() { return this.#parts.hostname; }
+    set hostname(value) { this.#change({ hostname: String(value) }); }
+    get port() { return this.#parts.port; }
+    set port(value) { this.#change({ port: String(value) }); }
+    get pathname() { return this.#parts.pathname; }
+    set pathname(value) {
+      value = String(value);
+      this.#change({ pathname: value.startsWith("/") ? value : "/" + value });
+    }
+    get search() { return this.#parts.search; }
+    set search(value) {
+      value = String(value);
+      this.#change({ search: value === "" || value === "?" ? "" : value.startsWith("?") ? value : "?" + value });
+    }
+    get searchParams() { return this.#searchParams; }
+    get hash() { return this.#parts.hash; }
+    set hash(value) {
+      value = String(value);
+      this.#change({ hash: value === "" || value === "#" ? "" : value.startsWith("#") ? value : "#" + value });
+    }
+
+    toString() { return this.href; }
+    toJSON() { return this.href; }
+  }
+
+  g.URL = URL;
+  g.URLSearchParams = URLSearchParams;
+})(globalThis);
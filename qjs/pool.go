@@ -0,0 +1,291 @@
+package qjs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/fastschema/qjs"
+)
+
+// runtimePool is a pool of bootstrapped QuickJS runtimes. Unlike qjs.Pool
+// it can be closed: runtimes returned to a closed pool are freed instead of
+// being reused, which lets Engine.Reload drain an old pool while renders
+// are still in flight.
+type runtimePool struct {
+	config  *config
+	metrics *metrics
+	slots   chan struct{} // bounds live runtimes when a max size is set
+	setup   func(rt *qjs.Runtime) error
+
+	mu     sync.Mutex
+	idle   []*qjs.Runtime
+	live   map[*qjs.Runtime]*runtimeInfo
+	closed bool
+
+	replacing sync.WaitGroup
+}
+
+// errPoolClosed is returned by get on a pool closed by Engine.Reload or
+// Engine.Close.
+var errPoolClosed = errors.New("qjs: runtime pool closed")
+
+type runtimeInfo struct {
+	renders int
+	memory  uint32 // as of the last put
+}
+
+func newRuntimePool(config *config, metrics *metrics, setup func(rt *qjs.Runtime) error) *runtimePool {
+	p := &runtimePool{
+		config:  config,
+		metrics: metrics,
+		setup:   setup,
+		live:    map[*qjs.Runtime]*runtimeInfo{},
+	}
+	if config.maxSize > 0 {
+		p.slots = make(chan struct{}, config.maxSize)
+	}
+	return p
+}
+
+// get returns an idle runtime or bootstraps a new one. When the pool is at
+// its max size, get waits for a runtime to be put back or for ctx to be
+// done. A closed pool returns errPoolClosed rather than booting a runtime
+// with its outdated bundle.
+func (p *runtimePool) get(ctx context.Context) (*qjs.Runtime, error) {
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.release()
+		return nil, errPoolClosed
+	}
+	if n := len(p.idle); n > 0 {
+		rt := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.live[rt].renders++
+		p.mu.Unlock()
+
+		rt.Call("QJS_UpdateStackTop", rt.Raw())
+		return rt, nil
+	}
+	p.mu.Unlock()
+
+	rt, err := p.create()
+	if err != nil {
+		p.release()
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.live[rt].renders++
+	p.mu.Unlock()
+
+	return rt, nil
+}
+
+// put returns rt to the pool. The runtime is freed if the pool is full or
+// closed. A runtime that is due for recycling is freed and replaced with a
+// fresh one in the background.
+func (p *runtimePool) put(rt *qjs.Runtime) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info := p.live[rt]
+	info.memory = rt.Mem().Size()
+
+	if p.closed || len(p.idle) >= p.config.size {
+		p.free(rt)
+		p.release()
+		return
+	}
+
+	if p.dueForRecycling(info) {
+		p.metrics.recycle()
+		p.freeAndReplace(rt)
+		return
+	}
+
+	rt.Call("QJS_UpdateStackTop", rt.Raw())
+	p.idle = append(p.idle, rt)
+	p.release()
+}
+
+// discard frees rt instead of returning it to the pool, for a runtime that
+// may be broken, and boots a fresh one in its place unless the pool is
+// closed.
+func (p *runtimePool) discard(rt *qjs.Runtime) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		p.free(rt)
+		p.release()
+		return
+	}
+	p.freeAndReplace(rt)
+}
+
+// freeAndReplace frees rt and boots a replacement in the background. The
+// replacement takes over rt's slot, so the pool never exceeds its max size.
+// p.mu must be held.
+func (p *runtimePool) freeAndReplace(rt *qjs.Runtime) {
+	p.free(rt)
+	p.replacing.Add(1)
+	go p.replace()
+}
+
+func (p *runtimePool) dueForRecycling(info *runtimeInfo) bool {
+	if p.config.recycleRenders > 0 && info.renders >= p.config.recycleRenders {
+		return true
+	}
+	return p.config.recycleMemory > 0 && info.memory >= p.config.recycleMemory
+}
+
+// replace boots a runtime in place of a recycled one. If booting fails, the
+// next get boots one instead and reports the error.
+func (p *runtimePool) replace() {
+	defer p.replacing.Done()
+
+	rt, err := p.create()
+	if err != nil {
+		p.release()
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || len(p.idle) >= p.config.size {
+		p.free(rt)
+	} else {
+		p.idle = append(p.idle, rt)
+	}
+	p.release()
+}
+
+// free closes rt. p.mu must be held.
+func (p *runtimePool) free(rt *qjs.Runtime) {
+	delete(p.live, rt)
+
+	// Closing a runtime whose heap a trap has corrupted panics before the
+	// wasm module is closed; the runtime is then left to the garbage
+	// collector.
+	defer func() {
+		if v := recover(); v != nil {
+			p.config.logger.LogAttrs(context.Background(), slog.LevelWarn, "qjs failed to close runtime",
+				slog.String("error", fmt.Sprint(v)),
+			)
+		}
+	}()
+	rt.Close()
+}
+
+func (p *runtimePool) release() {
+	if p.slots != nil {
+		<-p.slots
+	}
+}
+
+// warm boots runtimes until n of them are idle.
+func (p *runtimePool) warm(n int) error {
+	n = min(n, p.config.size)
+	if p.slots != nil {
+		n = min(n, cap(p.slots))
+	}
+
+	p.mu.Lock()
+	n -= len(p.idle)
+	p.mu.Unlock()
+
+	for range n {
+		if p.slots != nil {
+			p.slots <- struct{}{}
+		}
+
+		rt, err := p.create()
+		if err != nil {
+			p.release()
+			return err
+		}
+
+		p.put(rt)
+	}
+
+	return nil
+}
+
+// close frees all idle runtimes and waits for pending replacements.
+// Runtimes that are in use are freed when they are put back.
+func (p *runtimePool) close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+
+	for _, rt := range p.idle {
+		p.free(rt)
+	}
+	p.idle = nil
+	p.mu.Unlock()
+
+	p.replacing.Wait()
+}
+
+func (p *runtimePool) create() (*qjs.Runtime, error) {
+	rt, err := qjs.New(qjs.Option{
+		MemoryLimit:  p.config.memoryLimit,
+		MaxStackSize: p.config.maxStackSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.setup(rt); err != nil {
+		rt.Close()
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The pool may have been closed while the runtime booted.
+	if p.closed {
+		p.free(rt)
+		return nil, errPoolClosed
+	}
+	p.live[rt] = &runtimeInfo{memory: rt.Mem().Size()}
+
+	return rt, nil
+}
+
+// stats fills in the pool's share of s.
+func (p *runtimePool) stats(s *Stats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s.Size = len(p.live)
+	s.Idle = len(p.idle)
+	s.InUse = s.Size - s.Idle
+	if p.slots != nil {
+		s.MaxSize = cap(p.slots)
+	}
+
+	s.Memory = make([]uint32, 0, len(p.live))
+	for _, info := range p.live {
+		s.Memory = append(s.Memory, info.memory)
+	}
+	slices.Sort(s.Memory)
+}
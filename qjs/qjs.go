@@ -1,26 +1,64 @@
 package qjs
 
 import (
-	"io"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/fs"
+	"log/slog"
+	"math"
 	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/fastschema/qjs"
 
 	inertia "github.com/joetifa2003/inertigo"
 )
 
+// Engine is an inertia.SSREngine that renders pages with a pool of QuickJS
+// runtimes.
 type Engine struct {
-	pool *qjs.Pool
+	config  config
+	pool    atomic.Pointer[runtimePool]
+	metrics metrics
+
+	reloadMu  sync.Mutex
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
 }
 
 type config struct {
-	source string
-	size   int
+	load     func() ([]byte, error)
+	stat     func() (fs.FileInfo, error)
+	bytecode bool
+	size     int
+	maxSize  int
+	warmup   int
+	watch    time.Duration
+
+	memoryLimit    int
+	maxStackSize   int
+	recycleRenders int
+	recycleMemory  uint32
+
+	logger inertia.Logger
+
+	scripts    []bootstrapScript
+	hostFuncs  []hostFunc
+	webGlobals bool
 }
 
 type Option func(config *config) error
 
+// WithClusterSize sets how many idle runtimes the pool keeps for reuse.
+// Runtimes are booted lazily as renders need them. Without
+// WithMaxClusterSize, bursts above size boot extra runtimes that are freed
+// once they are done.
+// Default: 1
 func WithClusterSize(size int) Option {
 	return func(config *config) error {
 		config.size = size
@@ -30,36 +68,152 @@ func WithClusterSize(size int) Option {
 
 func WithSrc(source string) Option {
 	return func(config *config) error {
-		config.source = source
+		config.setBytes([]byte(source), false)
 		return nil
 	}
 }
 
 func WithSrcPath(path string) Option {
 	return func(config *config) error {
-		srcBytes, err := os.ReadFile(path)
-		if err != nil {
-			return err
-		}
-		config.source = string(srcBytes)
+		config.setFS(osFS{}, path, false)
 		return nil
 	}
 }
 
-func WithSrcFS(fs fs.FS, path string) Option {
+func WithSrcFS(fsys fs.FS, path string) Option {
 	return func(config *config) error {
-		f, err := fs.Open(path)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
+		config.setFS(fsys, path, false)
+		return nil
+	}
+}
 
-		srcBytes, err := io.ReadAll(f)
-		if err != nil {
-			return err
+// WithMaxClusterSize caps the number of live runtimes. Renders beyond the cap
+// wait for a runtime to be returned, or for their context to be done.
+// Default: no cap
+func WithMaxClusterSize(n int) Option {
+	return func(config *config) error {
+		config.maxSize = n
+		return nil
+	}
+}
+
+// WithWarmup boots n runtimes in New and in Reload, so the first renders do
+// not pay for booting them. n is capped at the cluster size.
+func WithWarmup(n int) Option {
+	return func(config *config) error {
+		config.warmup = n
+		return nil
+	}
+}
+
+// WithMemoryLimit sets the maximum heap size in bytes of each runtime. A
+// render that allocates past the limit fails with an out of memory error.
+// Default: no limit
+func WithMemoryLimit(bytes int) Option {
+	return func(config *config) error {
+		config.memoryLimit = bytes
+		return nil
+	}
+}
+
+// WithMaxStackSize sets the maximum stack size in bytes of each runtime.
+// Default: QuickJS's default
+func WithMaxStackSize(bytes int) Option {
+	return func(config *config) error {
+		config.maxStackSize = bytes
+		return nil
+	}
+}
+
+// WithRecycleAfter frees a runtime once it has served n renders and boots a
+// fresh one in its place, which bounds the damage of state that leaks
+// between renders.
+func WithRecycleAfter(n int) Option {
+	return func(config *config) error {
+		config.recycleRenders = n
+		return nil
+	}
+}
+
+// WithRecycleAbove frees a runtime once its memory has grown to at least
+// bytes and boots a fresh one in its place. QuickJS never returns memory
+// to the host, so this is the only way to reclaim it.
+func WithRecycleAbove(bytes int) Option {
+	return func(config *config) error {
+		if bytes < 0 || bytes > math.MaxUint32 {
+			return fmt.Errorf("qjs: recycle threshold out of range: %d", bytes)
 		}
+		config.recycleMemory = uint32(bytes)
+		return nil
+	}
+}
 
-		config.source = string(srcBytes)
+// WithBytecode loads an SSR bundle precompiled with Compile, skipping
+// compilation at startup.
+func WithBytecode(code []byte) Option {
+	return func(config *config) error {
+		config.setBytes(code, true)
+		return nil
+	}
+}
+
+// WithBytecodePath loads a precompiled SSR bundle from a file path.
+func WithBytecodePath(path string) Option {
+	return func(config *config) error {
+		config.setFS(osFS{}, path, true)
+		return nil
+	}
+}
+
+// WithBytecodeFS loads a precompiled SSR bundle from fsys, e.g. an embed.FS:
+//
+//	//go:embed dist/server/ssr.qjsc
+//	var serverFS embed.FS
+//
+//	qjs.New(qjs.WithBytecodeFS(serverFS, "dist/server/ssr.qjsc"))
+func WithBytecodeFS(fsys fs.FS, path string) Option {
+	return func(config *config) error {
+		config.setFS(fsys, path, true)
+		return nil
+	}
+}
+
+func (c *config) setBytes(data []byte, bytecode bool) {
+	c.load = func() ([]byte, error) { return data, nil }
+	c.stat = nil
+	c.bytecode = bytecode
+}
+
+func (c *config) setFS(fsys fs.FS, path string, bytecode bool) {
+	c.load = func() ([]byte, error) { return fs.ReadFile(fsys, path) }
+	c.stat = func() (fs.FileInfo, error) { return fs.Stat(fsys, path) }
+	c.bytecode = bytecode
+}
+
+// osFS reads paths from the OS file system as given, unlike os.DirFS which
+// rejects absolute and relative-to-parent paths.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) { return os.Open(name) }
+
+func (osFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+func (osFS) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+
+// WithWatch polls the SSR bundle set with WithSrcPath or WithSrcFS every
+// interval and reloads the engine when it changes.
+func WithWatch(interval time.Duration) Option {
+	return func(config *config) error {
+		config.watch = interval
+		return nil
+	}
+}
+
+// WithLogger sets the logger used to report reloads and, with
+// WithWebGlobals, console output from the SSR bundle.
+func WithLogger(logger inertia.Logger) Option {
+	return func(config *config) error {
+		config.logger = logger
 		return nil
 	}
 }
@@ -76,32 +230,168 @@ func New(options ...Option) (*Engine, error) {
 		}
 	}
 
-	tempRt, err := qjs.New()
+	if config.load == nil {
+		return nil, errors.New("qjs: no ssr source, use WithSrc, WithSrcPath, WithSrcFS or WithBytecode")
+	}
+
+	if config.maxSize > 0 && config.maxSize < config.size {
+		return nil, fmt.Errorf("qjs: max cluster size %d is smaller than the cluster size %d", config.maxSize, config.size)
+	}
+
+	if config.watch > 0 && config.stat == nil {
+		return nil, errors.New("qjs: WithWatch requires a bundle loaded from a path or fs.FS")
+	}
+
+	if config.logger == nil {
+		config.logger = slog.New(slog.DiscardHandler)
+	}
+
+	q := &Engine{
+		config: config,
+		done:   make(chan struct{}),
+	}
+
+	// Stat before loading so a change made while compiling is not missed.
+	var lastInfo fs.FileInfo
+	if config.watch > 0 {
+		lastInfo, _ = config.stat()
+	}
+
+	bundle, err := config.load()
 	if err != nil {
 		return nil, err
 	}
-	defer tempRt.Close()
 
-	byteCode, err := tempRt.Context().Compile("ssr.js", qjs.Code(config.source), qjs.TypeModule())
+	pool, err := q.newPool(bundle)
 	if err != nil {
 		return nil, err
 	}
 
-	pool := qjs.NewPool(config.size, qjs.Option{}, func(r *qjs.Runtime) error {
-		ctx := r.Context()
+	if err := pool.warm(config.warmup); err != nil {
+		pool.close()
+		return nil, err
+	}
+	q.pool.Store(pool)
+
+	if config.watch > 0 {
+		q.wg.Add(1)
+		go q.watch(lastInfo)
+	}
+
+	return q, nil
+}
+
+// Reload reads and, unless it is bytecode, compiles the SSR bundle again and swaps in a fresh pool
+// of runtimes. Renders already in flight finish on the old runtimes, which
+// are freed as they are returned. If the new bundle fails to compile or
+// boot, the engine keeps using the old one and the error is returned.
+func (q *Engine) Reload() error {
+	q.reloadMu.Lock()
+	defer q.reloadMu.Unlock()
+
+	bundle, err := q.config.load()
+	if err != nil {
+		return err
+	}
+
+	pool, err := q.newPool(bundle)
+	if err != nil {
+		return err
+	}
+
+	// Boot at least one runtime up front so a broken bundle never replaces
+	// a working one.
+	if err := pool.warm(max(q.config.warmup, 1)); err != nil {
+		pool.close()
+		return fmt.Errorf("qjs: reloaded bundle failed to start: %w", err)
+	}
+
+	q.pool.Swap(pool).close()
+
+	return nil
+}
+
+// Close stops watching the bundle and frees the pooled runtimes.
+func (q *Engine) Close() error {
+	q.closeOnce.Do(func() {
+		close(q.done)
+		q.wg.Wait()
+		q.pool.Load().close()
+	})
+	return nil
+}
+
+func (q *Engine) watch(lastInfo fs.FileInfo) {
+	defer q.wg.Done()
+
+	ctx := context.Background()
+	ticker := time.NewTicker(q.config.watch)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-ticker.C:
+		}
+
+		// The bundle may briefly be missing while it is rebuilt.
+		info, err := q.config.stat()
+		if err != nil {
+			continue
+		}
+		if lastInfo != nil && info.ModTime().Equal(lastInfo.ModTime()) && info.Size() == lastInfo.Size() {
+			continue
+		}
+		lastInfo = info
+
+		t1 := time.Now()
+		if err := q.Reload(); err != nil {
+			q.config.logger.LogAttrs(ctx, slog.LevelError, "qjs failed to reload ssr bundle",
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		q.config.logger.LogAttrs(ctx, slog.LevelInfo, "qjs reloaded ssr bundle",
+			slog.String("dur", time.Since(t1).String()),
+		)
+	}
+}
+
+// Compile compiles an SSR bundle to QuickJS bytecode that can be loaded with
+// WithBytecode, WithBytecodePath or WithBytecodeFS. Bytecode is only
+// compatible with the QuickJS build it was compiled with, so compile with the
+// same version of this package that loads it.
+func Compile(source string) ([]byte, error) {
+	tempRt, err := qjs.New()
+	if err != nil {
+		return nil, err
+	}
+	defer tempRt.Close()
 
-		val, err := ctx.Eval("polyfill_url.js", qjs.Code(`'use strict';(function(r){function x(){}function y(){}var z=String.fromCharCode,v={}.toString,A=v.call(r.SharedArrayBuffer),B=v(),q=r.Uint8Array,t=q||Array,w=q?ArrayBuffer:t,C=w.isView||function(g){return g&&"length"in g},D=v.call(w.prototype);w=y.prototype;var E=r.TextEncoder,a=new (q?Uint16Array:t)(32);x.prototype.decode=function(g){if(!C(g)){var l=v.call(g);if(l!==D&&l!==A&&l!==B)throw TypeError("Failed to execute 'decode' on 'TextDecoder': The provided value is not of type '(ArrayBuffer or ArrayBufferView)'");
-g=q?new t(g):g||[]}for(var f=l="",b=0,c=g.length|0,u=c-32|0,e,d,h=0,p=0,m,k=0,n=-1;b<c;){for(e=b<=u?32:c-b|0;k<e;b=b+1|0,k=k+1|0){d=g[b]&255;switch(d>>4){case 15:m=g[b=b+1|0]&255;if(2!==m>>6||247<d){b=b-1|0;break}h=(d&7)<<6|m&63;p=5;d=256;case 14:m=g[b=b+1|0]&255,h<<=6,h|=(d&15)<<6|m&63,p=2===m>>6?p+4|0:24,d=d+256&768;case 13:case 12:m=g[b=b+1|0]&255,h<<=6,h|=(d&31)<<6|m&63,p=p+7|0,b<c&&2===m>>6&&h>>p&&1114112>h?(d=h,h=h-65536|0,0<=h&&(n=(h>>10)+55296|0,d=(h&1023)+56320|0,31>k?(a[k]=n,k=k+1|0,n=-1):
-(m=n,n=d,d=m))):(d>>=8,b=b-d-1|0,d=65533),h=p=0,e=b<=u?32:c-b|0;default:a[k]=d;continue;case 11:case 10:case 9:case 8:}a[k]=65533}f+=z(a[0],a[1],a[2],a[3],a[4],a[5],a[6],a[7],a[8],a[9],a[10],a[11],a[12],a[13],a[14],a[15],a[16],a[17],a[18],a[19],a[20],a[21],a[22],a[23],a[24],a[25],a[26],a[27],a[28],a[29],a[30],a[31]);32>k&&(f=f.slice(0,k-32|0));if(b<c){if(a[0]=n,k=~n>>>31,n=-1,f.length<l.length)continue}else-1!==n&&(f+=z(n));l+=f;f=""}return l};w.encode=function(g){g=void 0===g?"":""+g;var l=g.length|
-0,f=new t((l<<1)+8|0),b,c=0,u=!q;for(b=0;b<l;b=b+1|0,c=c+1|0){var e=g.charCodeAt(b)|0;if(127>=e)f[c]=e;else{if(2047>=e)f[c]=192|e>>6;else{a:{if(55296<=e)if(56319>=e){var d=g.charCodeAt(b=b+1|0)|0;if(56320<=d&&57343>=d){e=(e<<10)+d-56613888|0;if(65535<e){f[c]=240|e>>18;f[c=c+1|0]=128|e>>12&63;f[c=c+1|0]=128|e>>6&63;f[c=c+1|0]=128|e&63;continue}break a}e=65533}else 57343>=e&&(e=65533);!u&&b<<1<c&&b<<1<(c-7|0)&&(u=!0,d=new t(3*l),d.set(f),f=d)}f[c]=224|e>>12;f[c=c+1|0]=128|e>>6&63}f[c=c+1|0]=128|e&63}}return q?
-f.subarray(0,c):f.slice(0,c)};E||(r.TextDecoder=x,r.TextEncoder=y)})(""+void 0==typeof global?""+void 0==typeof self?this:self:global);//AnonyCo
-//# sourceMappingURL=https://cdn.jsdelivr.net/gh/AnonyCo/FastestSmallestTextEncoderDecoder/EncoderDecoderTogether.min.js.map`))
+	return tempRt.Context().Compile("ssr.js", qjs.Code(source), qjs.TypeModule())
+}
+
+// newPool returns a pool whose runtimes run bundle, compiling it first
+// unless it is already bytecode.
+func (q *Engine) newPool(bundle []byte) (*runtimePool, error) {
+	byteCode := bundle
+	if !q.config.bytecode {
+		var err error
+		byteCode, err = Compile(string(bundle))
 		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newRuntimePool(&q.config, &q.metrics, func(r *qjs.Runtime) error {
+		ctx := r.Context()
+
+		if err := q.bootstrap(ctx); err != nil {
 			return err
 		}
-		defer val.Free()
 
-		val, err = ctx.Eval("ssr.js", qjs.TypeModule(), qjs.Bytecode(byteCode))
+		val, err := ctx.Eval("ssr.js", qjs.TypeModule(), qjs.Bytecode(byteCode))
 		if err != nil {
 			return err
 		}
@@ -114,29 +404,110 @@ f.subarray(0,c):f.slice(0,c)};E||(r.TextDecoder=x,r.TextEncoder=y)})(""+void 0==
 		defer val.Free()
 
 		return nil
-	})
-
-	return &Engine{
-		pool: pool,
-	}, nil
+	}), nil
 }
 
 func (q *Engine) Name() string { return "qjs" }
 
-func (q *Engine) Render(page inertia.PageObject) (inertia.RenderedPage, error) {
-	rt, err := q.pool.Get()
+// Stats returns a snapshot of the pool and render metrics.
+func (q *Engine) Stats() Stats {
+	var s Stats
+	q.pool.Load().stats(&s)
+	q.metrics.snapshot(&s)
+	return s
+}
+
+// Render renders the page using a runtime from the pool.
+// QuickJS cannot be interrupted mid-render, so when ctx is done Render returns
+// immediately and the runtime goes back to the pool once the abandoned render
+// finishes.
+func (q *Engine) Render(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+	if err := ctx.Err(); err != nil {
+		return inertia.RenderedPage{}, err
+	}
+
+	// The props belong to the caller once Render returns, which can be
+	// before an abandoned render reads them, so encode them up front.
+	pageJSON, err := json.Marshal(page)
 	if err != nil {
 		return inertia.RenderedPage{}, err
 	}
-	defer q.pool.Put(rt)
 
-	ctx := rt.Context()
+	type result struct {
+		page inertia.RenderedPage
+		err  error
+	}
 
-	input, err := qjs.ToJsValue(ctx, page)
+	done := make(chan result, 1)
+	go func() {
+		p, err := q.render(ctx, pageJSON)
+		done <- result{page: p, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.page, res.err
+	case <-ctx.Done():
+		return inertia.RenderedPage{}, ctx.Err()
+	}
+}
+
+func (q *Engine) render(ctx context.Context, pageJSON []byte) (inertia.RenderedPage, error) {
+	pool := q.pool.Load()
+
+	q.metrics.startWait()
+	t1 := time.Now()
+	rt, err := pool.get(ctx)
+	for errors.Is(err, errPoolClosed) {
+		// Reload swapped in a new pool after this one was loaded. If the
+		// pool is still current, the engine is closed.
+		next := q.pool.Load()
+		if next == pool {
+			break
+		}
+		pool = next
+		rt, err = pool.get(ctx)
+	}
+	q.metrics.endWait(time.Since(t1))
 	if err != nil {
+		if ctx.Err() == nil {
+			q.metrics.failed()
+		}
 		return inertia.RenderedPage{}, err
 	}
+
+	t1 = time.Now()
+	p, err := renderPage(rt, pageJSON)
+	q.metrics.rendered(time.Since(t1), err)
+
+	if errors.Is(err, errRenderPanicked) {
+		// A panic, such as a wasm trap, can leave the runtime corrupted, so
+		// it is replaced rather than reused.
+		pool.discard(rt)
+	} else {
+		pool.put(rt)
+	}
+
+	return p, err
+}
+
+// errRenderPanicked wraps the error of a render that panicked.
+var errRenderPanicked = errors.New("qjs render panicked")
+
+func renderPage(rt *qjs.Runtime, pageJSON []byte) (_ inertia.RenderedPage, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%w: %v", errRenderPanicked, v)
+		}
+	}()
+
+	ctx := rt.Context()
+
+	input := ctx.ParseJSON(string(pageJSON))
 	defer input.Free()
+	if !input.IsObject() {
+		return inertia.RenderedPage{}, errors.New("qjs: failed to parse page object")
+	}
 
 	renderPage := ctx.Global().GetPropertyStr("renderPage")
 	defer renderPage.Free()
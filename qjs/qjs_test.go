@@ -0,0 +1,454 @@
+package qjs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/qjs"
+)
+
+// bundle returns a minimal SSR bundle whose body is prefixed with label.
+func bundle(label string) string {
+	return `export async function renderPage(page) {
+		return { head: [], body: "` + label + `:" + page.component };
+	}`
+}
+
+func TestEngine_Render(t *testing.T) {
+	engine, err := qjs.New(qjs.WithSrc(bundle("v1")))
+	require.NoError(t, err)
+	defer engine.Close()
+
+	page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	require.NoError(t, err)
+	assert.Equal(t, "v1:Home", page.Body)
+}
+
+func TestEngine_RenderCancelled(t *testing.T) {
+	engine, err := qjs.New(qjs.WithSrc(bundle("v1")))
+	require.NoError(t, err)
+	defer engine.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = engine.Render(ctx, inertia.PageObject{Component: "Home"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNew_RequiresSource(t *testing.T) {
+	_, err := qjs.New()
+	assert.Error(t, err)
+
+	_, err = qjs.New(qjs.WithSrc(bundle("v1")), qjs.WithWatch(time.Second))
+	assert.Error(t, err, "watching needs a file to watch")
+}
+
+func TestEngine_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ssr.js")
+	require.NoError(t, os.WriteFile(path, []byte(bundle("v1")), 0o644))
+
+	engine, err := qjs.New(qjs.WithSrcPath(path), qjs.WithClusterSize(2))
+	require.NoError(t, err)
+	defer engine.Close()
+
+	render := func() string {
+		page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+		require.NoError(t, err)
+		return page.Body
+	}
+
+	assert.Equal(t, "v1:Home", render())
+
+	require.NoError(t, os.WriteFile(path, []byte(bundle("v2")), 0o644))
+	require.NoError(t, engine.Reload())
+	assert.Equal(t, "v2:Home", render())
+
+	// A broken bundle keeps the old one in place.
+	require.NoError(t, os.WriteFile(path, []byte(`export function renderPage( {`), 0o644))
+	assert.Error(t, engine.Reload())
+	assert.Equal(t, "v2:Home", render())
+}
+
+func TestEngine_ReloadWhileRendering(t *testing.T) {
+	engine, err := qjs.New(qjs.WithSrc(bundle("v1")), qjs.WithClusterSize(4))
+	require.NoError(t, err)
+	defer engine.Close()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 5 {
+				_, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	for range 3 {
+		assert.NoError(t, engine.Reload())
+	}
+
+	wg.Wait()
+}
+
+func TestEngine_RenderAfterClose(t *testing.T) {
+	engine, err := qjs.New(qjs.WithSrc(bundle("v1")))
+	require.NoError(t, err)
+	require.NoError(t, engine.Close())
+
+	_, err = engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	assert.Error(t, err, "a closed pool does not boot runtimes")
+	assert.Zero(t, engine.Stats().Size)
+}
+
+func TestEngine_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ssr.js")
+	require.NoError(t, os.WriteFile(path, []byte(bundle("v1")), 0o644))
+
+	engine, err := qjs.New(qjs.WithSrcPath(path), qjs.WithWatch(10*time.Millisecond))
+	require.NoError(t, err)
+	defer engine.Close()
+
+	require.NoError(t, os.WriteFile(path, []byte(bundle("v2-watched")), 0o644))
+
+	assert.Eventually(t, func() bool {
+		page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+		return err == nil && page.Body == "v2-watched:Home"
+	}, 10*time.Second, 20*time.Millisecond)
+}
+
+// evalBundle returns a bundle whose body is the JSON encoding of expr.
+func evalBundle(expr string) string {
+	return `export async function renderPage(page) {
+		return { head: [], body: JSON.stringify(await (async () => ` + expr + `)()) };
+	}`
+}
+
+func renderBody(t *testing.T, source string, options ...qjs.Option) string {
+	t.Helper()
+
+	engine, err := qjs.New(append([]qjs.Option{qjs.WithSrc(source)}, options...)...)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	require.NoError(t, err)
+	return page.Body
+}
+
+func TestWithWebGlobals(t *testing.T) {
+	t.Run("URL", func(t *testing.T) {
+		body := renderBody(t, evalBundle(`(() => {
+			const u = new URL("/users?page=2&sort=name#top", "HTTPS://Example.com:443/base/");
+			u.searchParams.set("page", "3");
+			return [u.href, u.origin, u.host, u.pathname, u.search, u.hash, u.searchParams.get("sort"), URL.canParse("nope")];
+		})()`), qjs.WithWebGlobals())
+		assert.JSONEq(t, `["https://example.com/users?page=3&sort=name#top","https://example.com","example.com","/users","?page=3&sort=name","#top","name",false]`, body)
+	})
+
+	t.Run("URLSearchParams", func(t *testing.T) {
+		body := renderBody(t, evalBundle(`(() => {
+			const p = new URLSearchParams("?a=1&b=x+y&a=2");
+			p.append("c", "&=");
+			return [p.getAll("a"), p.get("b"), p.has("z"), p.toString()];
+		})()`), qjs.WithWebGlobals())
+		assert.JSONEq(t, `[["1","2"],"x y",false,"a=1&b=x+y&a=2&c=%26%3D"]`, body)
+	})
+
+	t.Run("structuredClone", func(t *testing.T) {
+		body := renderBody(t, evalBundle(`(() => {
+			const a = { list: [1, { n: 2 }], when: new Date(0), tags: new Set(["x"]) };
+			a.self = a;
+			const b = structuredClone(a);
+			b.list[1].n = 3;
+			return [a.list[1].n, b.self === b, b.when instanceof Date, b.tags.has("x")];
+		})()`), qjs.WithWebGlobals())
+		assert.JSONEq(t, `[2,true,true,true]`, body)
+	})
+
+	t.Run("timers", func(t *testing.T) {
+		body := renderBody(t, evalBundle(`new Promise((resolve) => {
+			const order = [];
+			setTimeout(() => { order.push("timeout"); resolve(order); }, 0);
+			queueMicrotask(() => order.push("microtask"));
+		})`), qjs.WithWebGlobals())
+		assert.JSONEq(t, `["microtask","timeout"]`, body)
+	})
+
+	t.Run("console", func(t *testing.T) {
+		var buf syncBuffer
+		logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		renderBody(t, evalBundle(`(console.warn("careful", { n: 1 }), null)`), qjs.WithWebGlobals(), qjs.WithLogger(logger))
+		assert.Contains(t, buf.String(), `level=WARN msg="qjs console" msg="careful {\"n\":1}"`)
+	})
+}
+
+func TestWithHostFunc(t *testing.T) {
+	body := renderBody(t, evalBundle(`[greet("world", 2), (() => { try { fail() } catch (e) { return String(e.message) } })()]`),
+		qjs.WithHostFunc("greet", func(args []any) (any, error) {
+			return fmt.Sprintf("hello %v x%v", args[0], args[1]), nil
+		}),
+		qjs.WithHostFunc("fail", func(args []any) (any, error) {
+			return nil, errors.New("nope")
+		}),
+	)
+	assert.JSONEq(t, `["hello world x2","nope"]`, body)
+}
+
+func TestWithBootstrapScript(t *testing.T) {
+	body := renderBody(t, evalBundle(`[globalThis.first, globalThis.second]`),
+		qjs.WithBootstrapScript("first.js", `globalThis.first = "a";`),
+		qjs.WithBootstrapScript("second.js", `globalThis.second = globalThis.first + "b";`),
+	)
+	assert.JSONEq(t, `["a","ab"]`, body)
+
+	engine, err := qjs.New(qjs.WithSrc(bundle("v1")), qjs.WithBootstrapScript("broken.js", `throw new Error("boom")`))
+	require.NoError(t, err)
+	defer engine.Close()
+
+	_, err = engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	assert.ErrorContains(t, err, "broken.js")
+}
+
+// syncBuffer is a bytes.Buffer safe for use by concurrent writers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWithBytecode(t *testing.T) {
+	code, err := qjs.Compile(bundle("compiled"))
+	require.NoError(t, err)
+
+	t.Run("bytes", func(t *testing.T) {
+		engine, err := qjs.New(qjs.WithBytecode(code))
+		require.NoError(t, err)
+		defer engine.Close()
+
+		page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+		require.NoError(t, err)
+		assert.Equal(t, "compiled:Home", page.Body)
+	})
+
+	t.Run("fs", func(t *testing.T) {
+		fsys := fstest.MapFS{"dist/ssr.qjsc": &fstest.MapFile{Data: code}}
+
+		engine, err := qjs.New(qjs.WithBytecodeFS(fsys, "dist/ssr.qjsc"))
+		require.NoError(t, err)
+		defer engine.Close()
+
+		page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+		require.NoError(t, err)
+		assert.Equal(t, "compiled:Home", page.Body)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		engine, err := qjs.New(qjs.WithBytecode([]byte("not bytecode")))
+		require.NoError(t, err)
+		defer engine.Close()
+
+		_, err = engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+		assert.Error(t, err)
+	})
+}
+
+func TestEngine_Stats(t *testing.T) {
+	engine, err := qjs.New(
+		qjs.WithSrc(`export async function renderPage(page) {
+			if (page.component === "error") throw new Error("boom");
+			return { head: [], body: page.component };
+		}`),
+		qjs.WithClusterSize(2),
+	)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	stats := engine.Stats()
+	assert.Zero(t, stats.Size, "runtimes boot lazily")
+
+	_, err = engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	require.NoError(t, err)
+	_, err = engine.Render(context.Background(), inertia.PageObject{Component: "error"})
+	require.Error(t, err)
+
+	stats = engine.Stats()
+	assert.Equal(t, 1, stats.Size)
+	assert.Equal(t, 1, stats.Idle)
+	assert.Equal(t, 0, stats.InUse)
+	assert.Equal(t, uint64(2), stats.Renders)
+	assert.Equal(t, uint64(1), stats.Errors)
+	assert.Equal(t, uint64(2), stats.Wait.Count)
+	assert.Equal(t, uint64(2), stats.RenderDuration.Count)
+	require.Len(t, stats.Memory, 1)
+	assert.Positive(t, stats.Memory[0])
+
+	var total uint64
+	for _, b := range stats.RenderDuration.Buckets {
+		total += b.Count
+	}
+	assert.Equal(t, stats.RenderDuration.Count, total)
+}
+
+func TestWithWarmup(t *testing.T) {
+	engine, err := qjs.New(qjs.WithSrc(bundle("v1")), qjs.WithClusterSize(3), qjs.WithWarmup(2))
+	require.NoError(t, err)
+	defer engine.Close()
+
+	assert.Equal(t, 2, engine.Stats().Idle)
+
+	_, err = qjs.New(qjs.WithSrc(`export function renderPage() {}; throw new Error("boom")`), qjs.WithWarmup(1))
+	assert.Error(t, err, "warmup surfaces a broken bundle at startup")
+}
+
+func TestWithMaxClusterSize(t *testing.T) {
+	engine, err := qjs.New(
+		qjs.WithSrc(`export async function renderPage(page) {
+			const until = Date.now() + 100;
+			while (Date.now() < until) {}
+			return { head: [], body: page.component };
+		}`),
+		qjs.WithMaxClusterSize(1),
+	)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		_, err := engine.Render(context.Background(), inertia.PageObject{Component: "Slow"})
+		assert.NoError(t, err)
+	})
+
+	require.Eventually(t, func() bool { return engine.Stats().InUse == 1 }, 5*time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = engine.Render(ctx, inertia.PageObject{Component: "Home"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	wg.Wait()
+
+	page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	require.NoError(t, err)
+	assert.Equal(t, "Home", page.Body)
+
+	stats := engine.Stats()
+	assert.Equal(t, 1, stats.Size)
+	assert.Equal(t, 1, stats.MaxSize)
+	assert.Equal(t, 0, stats.Waiting)
+
+	_, err = qjs.New(qjs.WithSrc(bundle("v1")), qjs.WithClusterSize(4), qjs.WithMaxClusterSize(2))
+	assert.Error(t, err)
+}
+
+// counterBundle renders how many pages its runtime has rendered so far.
+const counterBundle = `export async function renderPage(page) {
+	globalThis.renders = (globalThis.renders || 0) + 1;
+	return { head: [], body: String(globalThis.renders) };
+}`
+
+func renderBodies(t *testing.T, engine *qjs.Engine, n int) []string {
+	t.Helper()
+
+	var bodies []string
+	for range n {
+		page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+		require.NoError(t, err)
+		bodies = append(bodies, page.Body)
+	}
+	return bodies
+}
+
+func TestWithRecycleAfter(t *testing.T) {
+	engine, err := qjs.New(qjs.WithSrc(counterBundle), qjs.WithRecycleAfter(2), qjs.WithMaxClusterSize(1))
+	require.NoError(t, err)
+	defer engine.Close()
+
+	assert.Equal(t, []string{"1", "2", "1", "2", "1"}, renderBodies(t, engine, 5))
+	assert.Equal(t, uint64(2), engine.Stats().Recycled)
+}
+
+func TestWithRecycleAbove(t *testing.T) {
+	engine, err := qjs.New(qjs.WithSrc(counterBundle), qjs.WithRecycleAbove(1), qjs.WithMaxClusterSize(1))
+	require.NoError(t, err)
+	defer engine.Close()
+
+	assert.Equal(t, []string{"1", "1", "1"}, renderBodies(t, engine, 3))
+	assert.Equal(t, uint64(3), engine.Stats().Recycled)
+
+	_, err = qjs.New(qjs.WithSrc(counterBundle), qjs.WithRecycleAbove(-1))
+	assert.Error(t, err)
+}
+
+func TestEngine_RenderPanicReplacesRuntime(t *testing.T) {
+	// With a stack limit past what the wasm stack can hold, deep recursion
+	// traps instead of throwing a RangeError, which panics the render.
+	engine, err := qjs.New(
+		qjs.WithSrc(`function recurse(n) { return recurse(n + 1) + 1 }
+		export async function renderPage(page) {
+			globalThis.renders = (globalThis.renders || 0) + 1;
+			if (page.component === "Crash") recurse(0);
+			return { head: [], body: String(globalThis.renders) };
+		}`),
+		qjs.WithMaxStackSize(1<<30),
+		qjs.WithMaxClusterSize(1),
+	)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	assert.Equal(t, []string{"1"}, renderBodies(t, engine, 1))
+
+	_, err = engine.Render(context.Background(), inertia.PageObject{Component: "Crash"})
+	assert.ErrorContains(t, err, "qjs render panicked")
+
+	assert.Equal(t, []string{"1", "2"}, renderBodies(t, engine, 2), "a fresh runtime serves the next render")
+}
+
+func TestWithMemoryLimit(t *testing.T) {
+	engine, err := qjs.New(
+		qjs.WithSrc(`export async function renderPage(page) {
+			if (page.component === "Hog") {
+				let s = "x";
+				for (let i = 0; i < 26; i++) s += s;
+			}
+			return { head: [], body: page.component };
+		}`),
+		qjs.WithMemoryLimit(16<<20),
+		qjs.WithMaxStackSize(256<<10),
+	)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	_, err = engine.Render(context.Background(), inertia.PageObject{Component: "Hog"})
+	assert.Error(t, err)
+
+	page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	require.NoError(t, err)
+	assert.Equal(t, "Home", page.Body)
+}
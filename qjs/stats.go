@@ -0,0 +1,156 @@
+package qjs
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats is a snapshot of an Engine's pool and render metrics.
+type Stats struct {
+	// Size is the number of live runtimes, idle or in use.
+	Size int `json:"size"`
+	// MaxSize is the cap set with WithMaxClusterSize, or 0 if there is none.
+	MaxSize int `json:"maxSize"`
+	Idle    int `json:"idle"`
+	InUse   int `json:"inUse"`
+	// Waiting is the number of renders waiting for a runtime.
+	Waiting int `json:"waiting"`
+
+	// Renders counts renders that got a runtime or failed to boot one;
+	// Errors counts those that failed.
+	Renders uint64 `json:"renders"`
+	Errors  uint64 `json:"errors"`
+	// Recycled counts runtimes replaced because of WithRecycleAfter or
+	// WithRecycleAbove.
+	Recycled uint64 `json:"recycled"`
+
+	// Wait is how long renders waited to get a runtime, including the time
+	// spent booting a new one.
+	Wait Histogram `json:"wait"`
+	// RenderDuration is how long renderPage took once a runtime was acquired.
+	RenderDuration Histogram `json:"renderDuration"`
+
+	// Memory is the WebAssembly memory size in bytes of each live runtime,
+	// as of when it was last returned to the pool, in ascending order.
+	Memory []uint32 `json:"memory"`
+}
+
+// Bucket counts the observations that fell at or below UpperBound and above
+// the previous bucket's bound. The last bucket has no upper bound and a
+// zero UpperBound.
+type Bucket struct {
+	UpperBound time.Duration `json:"upperBound"`
+	Count      uint64        `json:"count"`
+}
+
+// Histogram is a distribution of durations.
+type Histogram struct {
+	Buckets []Bucket      `json:"buckets"`
+	Count   uint64        `json:"count"`
+	Sum     time.Duration `json:"sum"`
+}
+
+var histogramBounds = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// metrics accumulates the Engine counters that outlive a pool across reloads.
+type metrics struct {
+	mu       sync.Mutex
+	waiting  int
+	renders  uint64
+	errors   uint64
+	recycled uint64
+	wait     histogram
+	render   histogram
+}
+
+type histogram struct {
+	counts [13]uint64 // one per bound plus the overflow bucket
+	count  uint64
+	sum    time.Duration
+}
+
+func (h *histogram) observe(d time.Duration) {
+	i := 0
+	for i < len(histogramBounds) && d > histogramBounds[i] {
+		i++
+	}
+	h.counts[i]++
+	h.count++
+	h.sum += d
+}
+
+func (h *histogram) snapshot() Histogram {
+	buckets := make([]Bucket, len(h.counts))
+	for i, count := range h.counts {
+		buckets[i].Count = count
+		if i < len(histogramBounds) {
+			buckets[i].UpperBound = histogramBounds[i]
+		}
+	}
+	return Histogram{Buckets: buckets, Count: h.count, Sum: h.sum}
+}
+
+func (m *metrics) startWait() {
+	m.mu.Lock()
+	m.waiting++
+	m.mu.Unlock()
+}
+
+func (m *metrics) endWait(d time.Duration) {
+	m.mu.Lock()
+	m.waiting--
+	m.wait.observe(d)
+	m.mu.Unlock()
+}
+
+func (m *metrics) rendered(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.renders++
+	if err != nil {
+		m.errors++
+	}
+	m.render.observe(d)
+}
+
+// failed records a render that failed before renderPage was called, e.g.
+// because a runtime could not be booted.
+func (m *metrics) failed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.renders++
+	m.errors++
+}
+
+func (m *metrics) recycle() {
+	m.mu.Lock()
+	m.recycled++
+	m.mu.Unlock()
+}
+
+func (m *metrics) snapshot(s *Stats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s.Waiting = m.waiting
+	s.Renders = m.renders
+	s.Errors = m.errors
+	s.Recycled = m.recycled
+	s.Wait = m.wait.snapshot()
+	s.RenderDuration = m.render.snapshot()
+}
@@ -0,0 +1,123 @@
+package inertia
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+)
+
+// TrackingWriter records whether the response has started, so a recovered
+// panic or a failed error page knows whether it can still respond. Adapters
+// use it to leave a written response alone when a handler fails.
+type TrackingWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// NewTrackingWriter wraps w in a TrackingWriter. If w already is one, it is
+// returned as is.
+func NewTrackingWriter(w http.ResponseWriter) *TrackingWriter {
+	if tw, ok := w.(*TrackingWriter); ok {
+		return tw
+	}
+	return &TrackingWriter{ResponseWriter: w}
+}
+
+func (w *TrackingWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *TrackingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *TrackingWriter) Flush() {
+	w.wroteHeader = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack hands the connection over, e.g. for a websocket upgrade. The
+// response counts as written from then on, so nothing is written to the
+// hijacked connection afterwards.
+func (w *TrackingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// ReadFrom keeps the wrapped writer's io.ReaderFrom, e.g. sendfile, in use.
+func (w *TrackingWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.wroteHeader = true
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(w.ResponseWriter, r)
+}
+
+func (w *TrackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Written reports whether the response has started, including writes made
+// directly to a wrapped writer that tracks them itself, such as a router's.
+func (w *TrackingWriter) Written() bool {
+	if w.wroteHeader {
+		return true
+	}
+	tracked, ok := w.ResponseWriter.(interface{ Written() bool })
+	return ok && tracked.Written()
+}
+
+// recoverPanic logs a recovered panic and responds with a 500.
+func (i *Inertia) recoverPanic(w *TrackingWriter, r *http.Request, v any) {
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+
+	stack := debug.Stack()
+	i.logger.LogAttrs(r.Context(), slog.LevelError, "panic recovered",
+		slog.String("path", r.URL.Path),
+		slog.String("panic", fmt.Sprint(v)),
+		slog.String("stack", string(stack)),
+	)
+
+	if w.Written() {
+		// Part of the response is out and cannot be replaced. Abort it so the
+		// client doesn't take it for a complete one.
+		panic(http.ErrAbortHandler)
+	}
+
+	err := fmt.Errorf("panic: %v\n\n%s", v, stack)
+
+	if r.Header.Get(XInertia) == "true" && i.bundler.IsDev() {
+		// The client shows a response without the X-Inertia header in a
+		// modal, which is where a stack trace is most useful.
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "<pre>%s</pre>", html.EscapeString(err.Error()))
+		return
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			i.logger.LogAttrs(r.Context(), slog.LevelError, "error page panicked",
+				slog.String("path", r.URL.Path),
+				slog.String("panic", fmt.Sprint(v)),
+			)
+			if !w.Written() {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}
+	}()
+
+	i.RenderError(w, r, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,202 @@
+package inertia_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+func newRecoveryInertia(t *testing.T, dev bool, logs *bytes.Buffer) *inertia.Inertia {
+	t.Helper()
+
+	bundler, err := vite.New(nil, vite.WithDevMode(dev))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler,
+		inertia.WithRecovery(true),
+		inertia.WithLogger(slog.New(slog.NewTextHandler(logs, nil))),
+		inertia.WithRootHtmlPathFS(fstest.MapFS{
+			"index.html": {Data: []byte(`<html><body>{{ .InertiaBody }}</body></html>`)},
+		}, "index.html"),
+	)
+	require.NoError(t, err)
+
+	return i
+}
+
+func TestMiddleware_Recovery(t *testing.T) {
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inertia.Share(r, "appName", inertia.Value("Inertigo"))
+		panic("boom")
+	})
+
+	t.Run("Inertia request in dev gets the stack", func(t *testing.T) {
+		var logs bytes.Buffer
+		i := newRecoveryInertia(t, true, &logs)
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+		i.Middleware(panicking).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Get(inertia.XInertia))
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, w.Body.String(), "<pre>panic: boom")
+		assert.Contains(t, w.Body.String(), "recovery_test.go")
+
+		assert.Contains(t, logs.String(), "panic recovered")
+		assert.Contains(t, logs.String(), "panic=boom")
+		assert.Contains(t, logs.String(), "stack=")
+	})
+
+	t.Run("Inertia request in production gets the error page", func(t *testing.T) {
+		var logs bytes.Buffer
+		i := newRecoveryInertia(t, false, &logs)
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+		i.Middleware(panicking).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "true", w.Header().Get(inertia.XInertia))
+
+		var page inertia.PageObject
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		assert.Equal(t, "Error", page.Component)
+		assert.EqualValues(t, http.StatusInternalServerError, page.Props["status"])
+		assert.Equal(t, "Inertigo", page.Props["appName"])
+		assert.NotContains(t, page.Props, "message")
+	})
+
+	t.Run("full load gets the error page", func(t *testing.T) {
+		var logs bytes.Buffer
+		i := newRecoveryInertia(t, true, &logs)
+
+		w := httptest.NewRecorder()
+		i.Middleware(panicking).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), `&#34;component&#34;:&#34;Error&#34;`)
+		assert.Contains(t, w.Body.String(), `panic: boom`)
+	})
+
+	t.Run("panic in a prop resolver", func(t *testing.T) {
+		var logs bytes.Buffer
+		i := newRecoveryInertia(t, false, &logs)
+
+		handler := i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = i.Render(w, r, "Home", inertia.Props{
+				"stats": inertia.Lazy(func(ctx context.Context) (any, error) {
+					panic("resolver failed")
+				}),
+			})
+		}))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, logs.String(), "resolver failed")
+	})
+
+	t.Run("aborts a response that has started", func(t *testing.T) {
+		var logs bytes.Buffer
+		i := newRecoveryInertia(t, true, &logs)
+
+		handler := i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("partial"))
+			panic("boom")
+		}))
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		})
+		assert.Contains(t, logs.String(), "panic recovered")
+	})
+
+	t.Run("hijacked connection", func(t *testing.T) {
+		var logs bytes.Buffer
+		i := newRecoveryInertia(t, true, &logs)
+
+		server, client := net.Pipe()
+		defer client.Close()
+
+		handler := i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hijacker, ok := w.(http.Hijacker)
+			require.True(t, ok, "the writer can be hijacked")
+
+			conn, _, err := hijacker.Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			panic("boom")
+		}))
+
+		w := httptest.NewRecorder()
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.ServeHTTP(hijackRecorder{w, server}, httptest.NewRequest("GET", "/", nil))
+		})
+		assert.Empty(t, w.Body.String(), "nothing is written after the hijack")
+	})
+
+	t.Run("shared props do not leak to the next request", func(t *testing.T) {
+		var logs bytes.Buffer
+		i := newRecoveryInertia(t, false, &logs)
+
+		i.Middleware(panicking).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		handler := i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, i.Render(w, r, "Home", nil))
+		}))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		var page inertia.PageObject
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		assert.NotContains(t, page.Props, "appName")
+	})
+}
+
+// hijackRecorder is a ResponseRecorder whose connection can be hijacked.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (w hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.conn, bufio.NewReadWriter(bufio.NewReader(w.conn), bufio.NewWriter(w.conn)), nil
+}
+
+func TestMiddleware_RecoveryDisabled(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	handler := i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	})
+}
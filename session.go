@@ -1,8 +1,10 @@
 package inertia
 
 import (
+	"container/list"
 	"crypto/rand"
 	"encoding/hex"
+	"log/slog"
 	"net/http"
 	"sync"
 	"time"
@@ -10,10 +12,15 @@ import (
 
 // Session defines the interface for session management.
 // Users can implement this with their preferred session library (e.g., gorilla/sessions, scs).
-// The default MemorySession implementation is provided for development purposes.
+// The default MemorySession implementation is provided for development purposes;
+// CookieSession works across multiple instances without any storage.
 type Session interface {
 	// Flash stores data that will be available for the next request only.
 	// The data is automatically removed after being retrieved via Get.
+	//
+	// Flash may be called several times in a request. Inertia merges the
+	// values, so each call has everything flashed so far in the request and
+	// replaces what the previous call stored.
 	Flash(w http.ResponseWriter, r *http.Request, values map[string]any) error
 
 	// Get retrieves a flashed value and removes it from the session.
@@ -28,19 +35,117 @@ const (
 
 // MemorySession is a thread-safe in-memory session implementation.
 // This is suitable for development and single-instance deployments.
-// For production with multiple instances, use a distributed session store.
+// For production with multiple instances, use CookieSession or a distributed session store.
+//
+// Flash data that is never read, e.g. from abandoned tabs or bots that don't
+// follow redirects, expires after a TTL, and the number of sessions is
+// capped, evicting the oldest first.
 type MemorySession struct {
-	mu         sync.RWMutex
-	store      map[string]map[string]any
+	mu         sync.Mutex
+	entries    map[string]*list.Element // of *memoryEntry
+	order      *list.List               // oldest Flash first
 	cookieName string
+
+	ttl             time.Duration
+	maxEntries      int
+	cleanupInterval time.Duration
+
+	evictions   uint64
+	expirations uint64
+
+	logger Logger
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type memoryEntry struct {
+	sessionID string
+	values    map[string]any
+	expires   time.Time // zero for no expiry
+}
+
+const (
+	defaultMemorySessionTTL        = time.Hour
+	defaultMemorySessionMaxEntries = 10_000
+)
+
+// MemorySessionOption configures a MemorySession.
+type MemorySessionOption func(m *MemorySession)
+
+// WithMemoryTTL sets how long flash data is kept if it is not read. The
+// default is one hour; 0 or less keeps it until it is read or evicted.
+func WithMemoryTTL(ttl time.Duration) MemorySessionOption {
+	return func(m *MemorySession) {
+		m.ttl = ttl
+	}
+}
+
+// WithMemoryMaxEntries caps the number of sessions with flash data. Beyond
+// it, the session flashed longest ago is evicted. The default is 10,000; 0
+// or less removes the cap.
+func WithMemoryMaxEntries(n int) MemorySessionOption {
+	return func(m *MemorySession) {
+		m.maxEntries = n
+	}
+}
+
+// WithMemoryCleanupInterval removes expired flash data every interval in
+// the background, until Close is called. Without it, expired data is
+// removed when it is read or evicted.
+func WithMemoryCleanupInterval(interval time.Duration) MemorySessionOption {
+	return func(m *MemorySession) {
+		m.cleanupInterval = interval
+	}
+}
+
+// WithMemoryLogger sets the logger that reports each eviction, at debug
+// level. Inertia's default session uses the logger set with WithLogger.
+func WithMemoryLogger(logger Logger) MemorySessionOption {
+	return func(m *MemorySession) {
+		m.logger = logger
+	}
+}
+
+// MemorySessionStats describes the contents of a MemorySession.
+type MemorySessionStats struct {
+	Entries     int    // sessions with flash data, including expired ones not yet removed
+	Evictions   uint64 // sessions evicted to stay within the cap
+	Expirations uint64 // sessions removed because their flash data expired
 }
 
 // NewMemorySession creates a new in-memory session store.
-func NewMemorySession(cookieName string) *MemorySession {
-	return &MemorySession{
-		store:      make(map[string]map[string]any),
+//
+// Unread flash data expires after one hour and at most 10,000 sessions are
+// kept, evicting the one flashed longest ago. Earlier versions kept
+// everything until it was read; pass WithMemoryTTL(0) and
+// WithMemoryMaxEntries(0) for that. Evictions are logged at debug level, see
+// WithMemoryLogger.
+func NewMemorySession(cookieName string, options ...MemorySessionOption) *MemorySession {
+	m := &MemorySession{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
 		cookieName: cookieName,
+		ttl:        defaultMemorySessionTTL,
+		maxEntries: defaultMemorySessionMaxEntries,
+	}
+
+	for _, opt := range options {
+		opt(m)
+	}
+
+	if m.logger == nil {
+		m.logger = slog.New(slog.DiscardHandler)
 	}
+
+	if m.cleanupInterval > 0 {
+		m.stop = make(chan struct{})
+		m.done = make(chan struct{})
+		go m.cleanup()
+	}
+
+	return m
 }
 
 // Flash stores a value in the session that will be available for the next request only.
@@ -50,16 +155,37 @@ func (m *MemorySession) Flash(w http.ResponseWriter, r *http.Request, values map
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.store[sessionID] == nil {
-		m.store[sessionID] = make(map[string]any)
+	var expires time.Time
+	if m.ttl > 0 {
+		expires = time.Now().Add(m.ttl)
+	}
+	if elem, ok := m.entries[sessionID]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.values = values
+		entry.expires = expires
+		m.order.MoveToBack(elem)
+		return nil
+	}
+
+	m.entries[sessionID] = m.order.PushBack(&memoryEntry{
+		sessionID: sessionID,
+		values:    values,
+		expires:   expires,
+	})
+
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		m.remove(m.order.Front())
+		m.evictions++
+		m.logger.LogAttrs(r.Context(), slog.LevelDebug, "flash data evicted, memory session is full",
+			slog.Int("max", m.maxEntries),
+		)
 	}
-	m.store[sessionID] = values
 
 	return nil
 }
 
 // Get retrieves a flashed value from the session and removes it.
-// Returns nil if the key doesn't exist.
+// Returns nil if the key doesn't exist or has expired.
 func (m *MemorySession) Get(w http.ResponseWriter, r *http.Request) (map[string]any, error) {
 	sessionID := m.getSessionID(r)
 	if sessionID == "" {
@@ -69,42 +195,131 @@ func (m *MemorySession) Get(w http.ResponseWriter, r *http.Request) (map[string]
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	sessionData, exists := m.store[sessionID]
+	elem, exists := m.entries[sessionID]
 	if !exists {
 		return nil, nil
 	}
 
-	delete(m.store, sessionID)
+	entry := m.remove(elem)
+	if entry.expired(time.Now()) {
+		m.expirations++
+		return nil, nil
+	}
 
-	return sessionData, nil
+	return entry.values, nil
 }
 
-func (m *MemorySession) getSessionID(r *http.Request) string {
-	cookie, err := r.Cookie(m.cookieName)
-	if err != nil {
-		return ""
+// Stats returns the number of sessions held and how many have been removed
+// without being read.
+func (m *MemorySession) Stats() MemorySessionStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return MemorySessionStats{
+		Entries:     m.order.Len(),
+		Evictions:   m.evictions,
+		Expirations: m.expirations,
 	}
-	return cookie.Value
 }
 
-// getOrCreateSessionID retrieves the existing session ID or creates a new one.
-func (m *MemorySession) getOrCreateSessionID(w http.ResponseWriter, r *http.Request) string {
-	if sessionID := m.getSessionID(r); sessionID != "" {
-		return sessionID
+// Close stops the background cleanup started by WithMemoryCleanupInterval.
+// The session can still be used afterwards.
+func (m *MemorySession) Close() error {
+	if m.stop == nil {
+		return nil
 	}
 
-	sessionID := generateSessionID()
+	m.closeOnce.Do(func() {
+		close(m.stop)
+		<-m.done
+	})
+	return nil
+}
+
+func (m *MemorySession) cleanup() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.removeExpired()
+		}
+	}
+}
+
+// removeExpired removes the expired entries. Every entry has the same TTL,
+// so they expire in the order they were flashed.
+func (m *MemorySession) removeExpired() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for elem := m.order.Front(); elem != nil; elem = m.order.Front() {
+		if !elem.Value.(*memoryEntry).expired(now) {
+			return
+		}
+		m.remove(elem)
+		m.expirations++
+	}
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+func (m *MemorySession) remove(elem *list.Element) *memoryEntry {
+	entry := m.order.Remove(elem).(*memoryEntry)
+	delete(m.entries, entry.sessionID)
+	return entry
+}
+
+func (m *MemorySession) getSessionID(r *http.Request) string {
+	return getSessionID(r, m.cookieName)
+}
 
-	http.SetCookie(w, &http.Cookie{
+// getOrCreateSessionID retrieves the existing session ID or creates a new one.
+func (m *MemorySession) getOrCreateSessionID(w http.ResponseWriter, r *http.Request) string {
+	return getOrCreateSessionID(w, r, &http.Cookie{
 		Name:     m.cookieName,
-		Value:    sessionID,
 		Path:     "/",
 		HttpOnly: true,
 		SameSite: http.SameSiteLaxMode,
 		Expires:  time.Now().Add(24 * time.Hour),
 	})
+}
 
-	return sessionID
+// getSessionID returns the session ID in the cookie cookieName, if any.
+func getSessionID(r *http.Request, cookieName string) string {
+	cookie, err := r.Cookie(cookieName)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// getOrCreateSessionID returns the session ID in the cookie named by
+// cookie, or sets cookie to a new one. A new ID set earlier in the same
+// response is reused, so successive flashes share a session.
+func getOrCreateSessionID(w http.ResponseWriter, r *http.Request, cookie *http.Cookie) string {
+	if sessionID := getSessionID(r, cookie.Name); sessionID != "" {
+		return sessionID
+	}
+
+	for _, header := range w.Header().Values("Set-Cookie") {
+		if set, err := http.ParseSetCookie(header); err == nil && set.Name == cookie.Name && set.Value != "" {
+			return set.Value
+		}
+	}
+
+	cookie.Value = generateSessionID()
+	http.SetCookie(w, cookie)
+
+	return cookie.Value
 }
 
 func generateSessionID() string {
@@ -0,0 +1,337 @@
+package inertia
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrSessionCookieTooLarge is returned by CookieSession.Flash when the
+	// encoded flash data does not fit in the cookie size limit.
+	ErrSessionCookieTooLarge = errors.New("inertia: flash data too large for session cookie")
+
+	// ErrInvalidSessionCookie is returned by CookieSession.Get for a cookie
+	// that was not signed with any of the session's secrets or was altered.
+	ErrInvalidSessionCookie = errors.New("inertia: invalid session cookie")
+)
+
+// MinCookieSecretLength is the minimum length of a CookieSession secret.
+const MinCookieSecretLength = 32
+
+// Serializer encodes flash data for a session store.
+type Serializer interface {
+	Marshal(values map[string]any) ([]byte, error)
+	Unmarshal(data []byte) (map[string]any, error)
+}
+
+// JSONSerializer encodes flash data as JSON. Numbers are decoded as float64,
+// as they would be in the page object.
+type JSONSerializer struct{}
+
+func (JSONSerializer) Marshal(values map[string]any) ([]byte, error) {
+	return json.Marshal(values)
+}
+
+func (JSONSerializer) Unmarshal(data []byte) (map[string]any, error) {
+	var values map[string]any
+	err := json.Unmarshal(data, &values)
+	return values, err
+}
+
+const (
+	defaultCookieMaxSize = 4000
+	defaultCookieTTL     = time.Hour
+
+	cookieExpiryLength = 8
+	cookieMACLength    = sha256.Size
+)
+
+// cookieKey holds the keys derived from one CookieSession secret.
+type cookieKey struct {
+	sign []byte
+	aead cipher.AEAD
+}
+
+func newCookieKey(secret []byte) (cookieKey, error) {
+	if len(secret) < MinCookieSecretLength {
+		return cookieKey{}, fmt.Errorf("inertia: cookie session secret must be at least %d bytes", MinCookieSecretLength)
+	}
+
+	sign, err := hkdf.Key(sha256.New, secret, nil, "inertigo cookie session signing", 32)
+	if err != nil {
+		return cookieKey{}, err
+	}
+
+	enc, err := hkdf.Key(sha256.New, secret, nil, "inertigo cookie session encryption", 32)
+	if err != nil {
+		return cookieKey{}, err
+	}
+
+	block, err := aes.NewCipher(enc)
+	if err != nil {
+		return cookieKey{}, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return cookieKey{}, err
+	}
+
+	return cookieKey{sign: sign, aead: aead}, nil
+}
+
+// CookieSession stores flash data in a cookie signed with HMAC-SHA256 and,
+// optionally, encrypted with AES-GCM. It needs no server-side storage, so it
+// works across instances behind a load balancer, as long as they share the
+// secret.
+//
+// Browsers limit cookies to about 4KB, so it suits flash messages and
+// validation errors, not large values.
+type CookieSession struct {
+	cookieName string
+	keys       []cookieKey // keys[0] signs new cookies
+	encrypt    bool
+	maxSize    int
+	ttl        time.Duration
+	secure     bool
+	serializer Serializer
+}
+
+// CookieSessionOption configures a CookieSession.
+type CookieSessionOption func(s *CookieSession) error
+
+// WithCookiePreviousSecrets accepts cookies signed with older secrets, so
+// the secret can be rotated without dropping flash data in flight. New
+// cookies are always signed with the current secret.
+func WithCookiePreviousSecrets(secrets ...[]byte) CookieSessionOption {
+	return func(s *CookieSession) error {
+		for _, secret := range secrets {
+			key, err := newCookieKey(secret)
+			if err != nil {
+				return err
+			}
+			s.keys = append(s.keys, key)
+		}
+		return nil
+	}
+}
+
+// WithCookieEncryption encrypts the flash data, so the client cannot read
+// it. Without it the data is only signed.
+func WithCookieEncryption(enabled bool) CookieSessionOption {
+	return func(s *CookieSession) error {
+		s.encrypt = enabled
+		return nil
+	}
+}
+
+// WithCookieMaxSize sets the largest cookie, name and value, Flash will
+// set. Flash returns ErrSessionCookieTooLarge beyond it. The default is 4000
+// bytes, which leaves room for the attributes within the 4KB browsers allow.
+func WithCookieMaxSize(n int) CookieSessionOption {
+	return func(s *CookieSession) error {
+		s.maxSize = n
+		return nil
+	}
+}
+
+// WithCookieTTL sets how long flash data is valid if it is not read. The
+// default is one hour.
+func WithCookieTTL(ttl time.Duration) CookieSessionOption {
+	return func(s *CookieSession) error {
+		s.ttl = ttl
+		return nil
+	}
+}
+
+// WithCookieSecure sets the Secure attribute, so the cookie is only sent
+// over HTTPS. Use it in production.
+func WithCookieSecure(secure bool) CookieSessionOption {
+	return func(s *CookieSession) error {
+		s.secure = secure
+		return nil
+	}
+}
+
+// WithCookieSerializer sets how flash data is encoded, JSONSerializer by
+// default.
+func WithCookieSerializer(serializer Serializer) CookieSessionOption {
+	return func(s *CookieSession) error {
+		s.serializer = serializer
+		return nil
+	}
+}
+
+// NewCookieSession creates a session storing flash data in the cookie
+// cookieName, signed with secret. The secret must be at least
+// MinCookieSecretLength random bytes and be kept private.
+func NewCookieSession(cookieName string, secret []byte, options ...CookieSessionOption) (*CookieSession, error) {
+	key, err := newCookieKey(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &CookieSession{
+		cookieName: cookieName,
+		keys:       []cookieKey{key},
+		maxSize:    defaultCookieMaxSize,
+		ttl:        defaultCookieTTL,
+		serializer: JSONSerializer{},
+	}
+
+	for _, opt := range options {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Flash stores values in the session cookie for the next request.
+func (s *CookieSession) Flash(w http.ResponseWriter, r *http.Request, values map[string]any) error {
+	data, err := s.serializer.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("inertia: encoding flash data: %w", err)
+	}
+
+	value, err := s.encode(data, time.Now().Add(s.ttl))
+	if err != nil {
+		return err
+	}
+
+	if size := len(s.cookieName) + len(value); size > s.maxSize {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrSessionCookieTooLarge, size, s.maxSize)
+	}
+
+	replaceCookie(w, s.cookie(value, int(s.ttl/time.Second)))
+	return nil
+}
+
+// Get returns the flash data from the session cookie and clears the cookie.
+// It returns ErrInvalidSessionCookie if the cookie has been tampered with.
+func (s *CookieSession) Get(w http.ResponseWriter, r *http.Request) (map[string]any, error) {
+	cookie, err := r.Cookie(s.cookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, nil
+	}
+
+	http.SetCookie(w, s.cookie("", -1))
+
+	data, expiry, err := s.decode(cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(expiry) {
+		return nil, nil
+	}
+
+	values, err := s.serializer.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("inertia: decoding flash data: %w", err)
+	}
+	return values, nil
+}
+
+func (s *CookieSession) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.cookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// replaceCookie sets cookie, dropping any cookie of the same name set
+// earlier in the response, e.g. by a previous Flash in the same request.
+func replaceCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	header := w.Header()
+	var kept []string
+	for _, line := range header.Values("Set-Cookie") {
+		if set, err := http.ParseSetCookie(line); err == nil && set.Name == cookie.Name {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	header["Set-Cookie"] = kept
+	http.SetCookie(w, cookie)
+}
+
+// encode returns the cookie value for data: the expiry, the data, which is
+// encrypted as nonce and ciphertext if enabled, and an HMAC of both.
+func (s *CookieSession) encode(data []byte, expiry time.Time) (string, error) {
+	key := s.keys[0]
+
+	payload := binary.BigEndian.AppendUint64(nil, uint64(expiry.Unix()))
+	if s.encrypt {
+		nonce := make([]byte, key.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		payload = append(payload, nonce...)
+		payload = key.aead.Seal(payload, nonce, data, []byte(s.cookieName))
+	} else {
+		payload = append(payload, data...)
+	}
+
+	payload = append(payload, s.mac(key, payload)...)
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// decode verifies value against each key and returns its data and expiry.
+func (s *CookieSession) decode(value string) ([]byte, time.Time, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) < cookieExpiryLength+cookieMACLength {
+		return nil, time.Time{}, ErrInvalidSessionCookie
+	}
+
+	payload, sum := raw[:len(raw)-cookieMACLength], raw[len(raw)-cookieMACLength:]
+	expiry := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+	body := payload[cookieExpiryLength:]
+
+	for _, key := range s.keys {
+		if !hmac.Equal(sum, s.mac(key, payload)) {
+			continue
+		}
+
+		if !s.encrypt {
+			return body, expiry, nil
+		}
+
+		nonceSize := key.aead.NonceSize()
+		if len(body) < nonceSize {
+			return nil, time.Time{}, ErrInvalidSessionCookie
+		}
+		data, err := key.aead.Open(nil, body[:nonceSize], body[nonceSize:], []byte(s.cookieName))
+		if err != nil {
+			return nil, time.Time{}, ErrInvalidSessionCookie
+		}
+		return data, expiry, nil
+	}
+
+	return nil, time.Time{}, ErrInvalidSessionCookie
+}
+
+// mac signs payload together with the cookie name, so a value cannot be
+// moved to another cookie.
+func (s *CookieSession) mac(key cookieKey, payload []byte) []byte {
+	h := hmac.New(sha256.New, key.sign)
+	h.Write([]byte(s.cookieName))
+	h.Write([]byte{0})
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,228 @@
+package inertia_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+var (
+	cookieSecret    = []byte("0123456789abcdef0123456789abcdef")
+	newCookieSecret = []byte("fedcba9876543210fedcba9876543210")
+)
+
+// flashCookie flashes values with s and returns the cookie it sets.
+func flashCookie(t *testing.T, s inertia.Session, values map[string]any) *http.Cookie {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	require.NoError(t, s.Flash(w, httptest.NewRequest("POST", "/", nil), values))
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	return cookies[0]
+}
+
+// getWithCookie reads the flash data of a request carrying cookie.
+func getWithCookie(s inertia.Session, cookie *http.Cookie) (map[string]any, *httptest.ResponseRecorder, error) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	values, err := s.Get(w, r)
+	return values, w, err
+}
+
+func TestCookieSession(t *testing.T) {
+	t.Run("signed", func(t *testing.T) {
+		s, err := inertia.NewCookieSession("flash", cookieSecret)
+		require.NoError(t, err)
+
+		cookie := flashCookie(t, s, map[string]any{"success": "Saved"})
+		assert.True(t, cookie.HttpOnly)
+
+		raw, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), `"success":"Saved"`)
+
+		values, w, err := getWithCookie(s, cookie)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"success": "Saved"}, values)
+
+		cleared := w.Result().Cookies()
+		require.Len(t, cleared, 1)
+		assert.Equal(t, "flash", cleared[0].Name)
+		assert.Equal(t, -1, cleared[0].MaxAge)
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		s, err := inertia.NewCookieSession("flash", cookieSecret, inertia.WithCookieEncryption(true))
+		require.NoError(t, err)
+
+		cookie := flashCookie(t, s, map[string]any{"success": "Saved"})
+
+		raw, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), "Saved")
+
+		values, _, err := getWithCookie(s, cookie)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"success": "Saved"}, values)
+	})
+
+	t.Run("rejects tampered cookies", func(t *testing.T) {
+		for _, encrypt := range []bool{false, true} {
+			s, err := inertia.NewCookieSession("flash", cookieSecret, inertia.WithCookieEncryption(encrypt))
+			require.NoError(t, err)
+
+			cookie := flashCookie(t, s, map[string]any{"role": "user"})
+			raw, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+			require.NoError(t, err)
+			raw = bytes.Replace(raw, []byte("user"), []byte("root"), 1)
+			raw[10] ^= 1
+			cookie.Value = base64.RawURLEncoding.EncodeToString(raw)
+
+			values, w, err := getWithCookie(s, cookie)
+			assert.ErrorIs(t, err, inertia.ErrInvalidSessionCookie)
+			assert.Nil(t, values)
+			assert.Len(t, w.Result().Cookies(), 1, "the invalid cookie is cleared")
+
+			cookie.Value = "not base64!"
+			_, _, err = getWithCookie(s, cookie)
+			assert.ErrorIs(t, err, inertia.ErrInvalidSessionCookie)
+		}
+	})
+
+	t.Run("rejects cookies renamed from another session", func(t *testing.T) {
+		s, err := inertia.NewCookieSession("flash", cookieSecret)
+		require.NoError(t, err)
+		other, err := inertia.NewCookieSession("other", cookieSecret)
+		require.NoError(t, err)
+
+		cookie := flashCookie(t, other, map[string]any{"success": "Saved"})
+		cookie.Name = "flash"
+
+		_, _, err = getWithCookie(s, cookie)
+		assert.ErrorIs(t, err, inertia.ErrInvalidSessionCookie)
+	})
+
+	t.Run("key rotation", func(t *testing.T) {
+		for _, encrypt := range []bool{false, true} {
+			old, err := inertia.NewCookieSession("flash", cookieSecret, inertia.WithCookieEncryption(encrypt))
+			require.NoError(t, err)
+			rotated, err := inertia.NewCookieSession("flash", newCookieSecret,
+				inertia.WithCookieEncryption(encrypt),
+				inertia.WithCookiePreviousSecrets(cookieSecret),
+			)
+			require.NoError(t, err)
+
+			values, _, err := getWithCookie(rotated, flashCookie(t, old, map[string]any{"success": "Saved"}))
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"success": "Saved"}, values)
+
+			_, _, err = getWithCookie(old, flashCookie(t, rotated, map[string]any{"success": "Saved"}))
+			assert.ErrorIs(t, err, inertia.ErrInvalidSessionCookie)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		s, err := inertia.NewCookieSession("flash", cookieSecret, inertia.WithCookieTTL(-time.Minute))
+		require.NoError(t, err)
+
+		values, _, err := getWithCookie(s, flashCookie(t, s, map[string]any{"success": "Saved"}))
+		require.NoError(t, err)
+		assert.Nil(t, values)
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		s, err := inertia.NewCookieSession("flash", cookieSecret, inertia.WithCookieMaxSize(200))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		err = s.Flash(w, httptest.NewRequest("POST", "/", nil), map[string]any{
+			"message": strings.Repeat("a", 200),
+		})
+		assert.ErrorIs(t, err, inertia.ErrSessionCookieTooLarge)
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("custom serializer", func(t *testing.T) {
+		s, err := inertia.NewCookieSession("flash", cookieSecret, inertia.WithCookieSerializer(upperSerializer{}))
+		require.NoError(t, err)
+
+		cookie := flashCookie(t, s, map[string]any{"success": "saved"})
+		raw, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), "SAVED")
+
+		values, _, err := getWithCookie(s, cookie)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"SUCCESS": "SAVED"}, values)
+	})
+
+	t.Run("short secret", func(t *testing.T) {
+		_, err := inertia.NewCookieSession("flash", []byte("too short"))
+		assert.Error(t, err)
+
+		_, err = inertia.NewCookieSession("flash", cookieSecret, inertia.WithCookiePreviousSecrets([]byte("too short")))
+		assert.Error(t, err)
+	})
+}
+
+// upperSerializer is a JSON serializer that upper-cases everything.
+type upperSerializer struct{}
+
+func (upperSerializer) Marshal(values map[string]any) ([]byte, error) {
+	data, err := json.Marshal(values)
+	return bytes.ToUpper(data), err
+}
+
+func (upperSerializer) Unmarshal(data []byte) (map[string]any, error) {
+	return inertia.JSONSerializer{}.Unmarshal(data)
+}
+
+func TestCookieSession_FlashAcrossInstances(t *testing.T) {
+	newInstance := func() *inertia.Inertia {
+		bundler, err := vite.New(nil, vite.WithDevMode(true))
+		require.NoError(t, err)
+
+		session, err := inertia.NewCookieSession("flash", cookieSecret, inertia.WithCookieEncryption(true))
+		require.NoError(t, err)
+
+		i, err := inertia.New(bundler, inertia.WithSession(session))
+		require.NoError(t, err)
+		return i
+	}
+
+	first := newInstance()
+	w := httptest.NewRecorder()
+	first.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, first.RenderErrors(w, r, map[string]any{"name": "Name is required"}))
+	})).ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+
+	second := newInstance()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(inertia.XInertia, "true")
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	second.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, second.Render(w, r, "users/Create", nil))
+	})).ServeHTTP(w, req)
+
+	var page inertia.PageObject
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+	assert.Equal(t, map[string]any{"name": "Name is required"}, page.Props["errors"])
+}
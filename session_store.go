@@ -0,0 +1,134 @@
+package inertia
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SessionStore is a key-value store with expiry for StoreSession, such as
+// Redis or a SQL table. See the stores directory for implementations.
+type SessionStore interface {
+	// Set stores value under key, replacing any previous value, until ttl
+	// has passed. A ttl of 0 or less keeps the value until it is read.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// GetDel returns the value under key and deletes it, atomically, so a
+	// value is only ever returned once. It returns nil if the key doesn't
+	// exist or has expired.
+	GetDel(ctx context.Context, key string) ([]byte, error)
+}
+
+const (
+	defaultStoreKeyPrefix = "inertia:flash:"
+	defaultStoreTTL       = time.Hour
+)
+
+// StoreSession keeps flash data in a SessionStore, under a random session
+// ID kept in a cookie. With a shared store, such as Redis or a database,
+// flash data works across instances behind a load balancer.
+type StoreSession struct {
+	store      SessionStore
+	cookieName string
+	keyPrefix  string
+	ttl        time.Duration
+	secure     bool
+	serializer Serializer
+}
+
+// StoreSessionOption configures a StoreSession.
+type StoreSessionOption func(s *StoreSession)
+
+// WithStoreKeyPrefix sets the prefix of the store keys, "inertia:flash:" by
+// default.
+func WithStoreKeyPrefix(prefix string) StoreSessionOption {
+	return func(s *StoreSession) {
+		s.keyPrefix = prefix
+	}
+}
+
+// WithStoreTTL sets how long flash data is kept if it is not read. The
+// default is one hour; 0 or less keeps it until it is read.
+func WithStoreTTL(ttl time.Duration) StoreSessionOption {
+	return func(s *StoreSession) {
+		s.ttl = ttl
+	}
+}
+
+// WithStoreCookieSecure sets the Secure attribute of the session ID cookie,
+// so it is only sent over HTTPS. Use it in production.
+func WithStoreCookieSecure(secure bool) StoreSessionOption {
+	return func(s *StoreSession) {
+		s.secure = secure
+	}
+}
+
+// WithStoreSerializer sets how flash data is encoded, JSONSerializer by
+// default.
+func WithStoreSerializer(serializer Serializer) StoreSessionOption {
+	return func(s *StoreSession) {
+		s.serializer = serializer
+	}
+}
+
+// NewStoreSession creates a session keeping flash data in store, with the
+// session ID in the cookie cookieName.
+func NewStoreSession(cookieName string, store SessionStore, options ...StoreSessionOption) *StoreSession {
+	s := &StoreSession{
+		store:      store,
+		cookieName: cookieName,
+		keyPrefix:  defaultStoreKeyPrefix,
+		ttl:        defaultStoreTTL,
+		serializer: JSONSerializer{},
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
+}
+
+// Flash stores values in the store for the next request.
+func (s *StoreSession) Flash(w http.ResponseWriter, r *http.Request, values map[string]any) error {
+	data, err := s.serializer.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("inertia: encoding flash data: %w", err)
+	}
+
+	sessionID := getOrCreateSessionID(w, r, &http.Cookie{
+		Name:     s.cookieName,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if err := s.store.Set(r.Context(), s.keyPrefix+sessionID, data, s.ttl); err != nil {
+		return fmt.Errorf("inertia: storing flash data: %w", err)
+	}
+	return nil
+}
+
+// Get returns the flash data from the store and deletes it.
+func (s *StoreSession) Get(w http.ResponseWriter, r *http.Request) (map[string]any, error) {
+	sessionID := getSessionID(r, s.cookieName)
+	if sessionID == "" {
+		return nil, nil
+	}
+
+	data, err := s.store.GetDel(r.Context(), s.keyPrefix+sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("inertia: loading flash data: %w", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	values, err := s.serializer.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("inertia: decoding flash data: %w", err)
+	}
+	return values, nil
+}
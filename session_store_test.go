@@ -0,0 +1,132 @@
+package inertia_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/sessiontest"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+func TestSessiontestStore(t *testing.T) {
+	sessiontest.RunStoreTests(t, sessiontest.NewStore())
+}
+
+func TestStoreSession(t *testing.T) {
+	t.Run("flash and get", func(t *testing.T) {
+		store := sessiontest.NewStore()
+		s := inertia.NewStoreSession("sid", store, inertia.WithStoreKeyPrefix("flash:"))
+
+		cookie := flashCookie(t, s, map[string]any{"success": "Saved"})
+		assert.Equal(t, "sid", cookie.Name)
+		assert.True(t, cookie.HttpOnly)
+
+		value, err := store.GetDel(context.Background(), "flash:"+cookie.Value)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"success":"Saved"}`, string(value))
+
+		cookie = flashCookie(t, s, map[string]any{"success": "Saved"})
+		values, _, err := getWithCookie(s, cookie)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"success": "Saved"}, values)
+
+		values, _, err = getWithCookie(s, cookie)
+		require.NoError(t, err)
+		assert.Nil(t, values, "flash data is read once")
+		assert.Zero(t, store.Len())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		s := inertia.NewStoreSession("sid", sessiontest.NewStore(), inertia.WithStoreTTL(time.Millisecond))
+
+		cookie := flashCookie(t, s, map[string]any{"success": "Saved"})
+		time.Sleep(5 * time.Millisecond)
+
+		values, _, err := getWithCookie(s, cookie)
+		require.NoError(t, err)
+		assert.Nil(t, values)
+	})
+
+	t.Run("no ttl", func(t *testing.T) {
+		s := inertia.NewStoreSession("sid", sessiontest.NewStore(), inertia.WithStoreTTL(0))
+
+		values, _, err := getWithCookie(s, flashCookie(t, s, map[string]any{"success": "Saved"}))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"success": "Saved"}, values)
+	})
+
+	t.Run("no cookie", func(t *testing.T) {
+		s := inertia.NewStoreSession("sid", sessiontest.NewStore())
+
+		values, err := s.Get(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+		assert.Nil(t, values)
+	})
+
+	t.Run("store errors", func(t *testing.T) {
+		errDown := errors.New("store is down")
+		s := inertia.NewStoreSession("sid", failingStore{err: errDown})
+
+		err := s.Flash(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil), map[string]any{"success": "Saved"})
+		assert.ErrorIs(t, err, errDown)
+
+		_, _, err = getWithCookie(s, &http.Cookie{Name: "sid", Value: "abc"})
+		assert.ErrorIs(t, err, errDown)
+	})
+}
+
+type failingStore struct {
+	err error
+}
+
+func (s failingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.err
+}
+
+func (s failingStore) GetDel(ctx context.Context, key string) ([]byte, error) {
+	return nil, s.err
+}
+
+func TestStoreSession_FlashAcrossInstances(t *testing.T) {
+	store := sessiontest.NewStore()
+
+	newInstance := func() *inertia.Inertia {
+		bundler, err := vite.New(nil, vite.WithDevMode(true))
+		require.NoError(t, err)
+
+		i, err := inertia.New(bundler, inertia.WithSession(inertia.NewStoreSession("sid", store)))
+		require.NoError(t, err)
+		return i
+	}
+
+	first := newInstance()
+	w := httptest.NewRecorder()
+	first.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, first.RenderErrors(w, r, map[string]any{"name": "Name is required"}))
+	})).ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+
+	second := newInstance()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(inertia.XInertia, "true")
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	second.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, second.Render(w, r, "users/Create", nil))
+	})).ServeHTTP(w, req)
+
+	var page inertia.PageObject
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+	assert.Equal(t, map[string]any{"name": "Name is required"}, page.Props["errors"])
+}
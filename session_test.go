@@ -0,0 +1,256 @@
+package inertia_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/sessiontest"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+func TestMemorySession(t *testing.T) {
+	t.Run("flash and get once", func(t *testing.T) {
+		s := inertia.NewMemorySession("sid")
+
+		cookie := flashCookie(t, s, map[string]any{"success": "Saved"})
+		assert.Equal(t, 1, s.Stats().Entries)
+
+		values, _, err := getWithCookie(s, cookie)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"success": "Saved"}, values)
+
+		values, _, err = getWithCookie(s, cookie)
+		require.NoError(t, err)
+		assert.Nil(t, values)
+		assert.Equal(t, inertia.MemorySessionStats{}, s.Stats())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		s := inertia.NewMemorySession("sid", inertia.WithMemoryTTL(time.Millisecond))
+
+		cookie := flashCookie(t, s, map[string]any{"success": "Saved"})
+		time.Sleep(5 * time.Millisecond)
+
+		values, _, err := getWithCookie(s, cookie)
+		require.NoError(t, err)
+		assert.Nil(t, values)
+		assert.Equal(t, inertia.MemorySessionStats{Expirations: 1}, s.Stats())
+	})
+
+	t.Run("no ttl", func(t *testing.T) {
+		s := inertia.NewMemorySession("sid",
+			inertia.WithMemoryTTL(0),
+			inertia.WithMemoryCleanupInterval(time.Millisecond),
+		)
+		defer s.Close()
+
+		cookie := flashCookie(t, s, map[string]any{"success": "Saved"})
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, inertia.MemorySessionStats{Entries: 1}, s.Stats(), "the cleanup keeps it")
+
+		values, _, err := getWithCookie(s, cookie)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"success": "Saved"}, values)
+	})
+
+	t.Run("evicts the oldest beyond the cap", func(t *testing.T) {
+		var logs bytes.Buffer
+		s := inertia.NewMemorySession("sid",
+			inertia.WithMemoryMaxEntries(2),
+			inertia.WithMemoryLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		)
+
+		first := flashCookie(t, s, map[string]any{"n": 1})
+		second := flashCookie(t, s, map[string]any{"n": 2})
+
+		// Flashing again makes the first session the newest.
+		r := httptest.NewRequest("POST", "/", nil)
+		r.AddCookie(first)
+		require.NoError(t, s.Flash(httptest.NewRecorder(), r, map[string]any{"n": 3}))
+
+		third := flashCookie(t, s, map[string]any{"n": 4})
+		assert.Equal(t, inertia.MemorySessionStats{Entries: 2, Evictions: 1}, s.Stats())
+		assert.Equal(t, 1, strings.Count(logs.String(), "flash data evicted"))
+
+		for cookie, want := range map[*http.Cookie]map[string]any{
+			first:  {"n": 3},
+			second: nil,
+			third:  {"n": 4},
+		} {
+			values, _, err := getWithCookie(s, cookie)
+			require.NoError(t, err)
+			assert.Equal(t, want, values)
+		}
+	})
+
+	t.Run("background cleanup", func(t *testing.T) {
+		s := inertia.NewMemorySession("sid",
+			inertia.WithMemoryTTL(20*time.Millisecond),
+			inertia.WithMemoryCleanupInterval(5*time.Millisecond),
+		)
+		defer s.Close()
+
+		for range 3 {
+			flashCookie(t, s, map[string]any{"success": "Saved"})
+		}
+		assert.Equal(t, 3, s.Stats().Entries)
+
+		assert.Eventually(t, func() bool {
+			return s.Stats() == inertia.MemorySessionStats{Expirations: 3}
+		}, time.Second, 5*time.Millisecond)
+
+		require.NoError(t, s.Close())
+		require.NoError(t, s.Close())
+	})
+
+	t.Run("close without cleanup", func(t *testing.T) {
+		assert.NoError(t, inertia.NewMemorySession("sid").Close())
+	})
+}
+
+// browser sends requests through an Inertia instance, keeping cookies
+// between them.
+type browser struct {
+	t       *testing.T
+	i       *inertia.Inertia
+	cookies map[string]*http.Cookie
+}
+
+func newBrowser(t *testing.T, session inertia.Session) *browser {
+	t.Helper()
+
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler, inertia.WithSession(session))
+	require.NoError(t, err)
+
+	return &browser{t: t, i: i, cookies: make(map[string]*http.Cookie)}
+}
+
+func (b *browser) do(method string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	b.t.Helper()
+
+	req := httptest.NewRequest(method, "/", nil)
+	req.Header.Set(inertia.XInertia, "true")
+	for _, cookie := range b.cookies {
+		req.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	b.i.Middleware(handler).ServeHTTP(w, req)
+
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(b.cookies, cookie.Name)
+		} else {
+			b.cookies[cookie.Name] = cookie
+		}
+	}
+	return w
+}
+
+// flash returns the flash data and errors of a page rendered by the next
+// request.
+func (b *browser) flash() (map[string]any, any) {
+	b.t.Helper()
+
+	w := b.do("GET", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(b.t, b.i.Render(w, r, "Home", nil))
+	})
+
+	var page inertia.PageObject
+	require.NoError(b.t, json.NewDecoder(w.Body).Decode(&page))
+	return page.Flash, page.Props["errors"]
+}
+
+func TestFlash_Merge(t *testing.T) {
+	cookieSession, err := inertia.NewCookieSession("flash", cookieSecret)
+	require.NoError(t, err)
+
+	sessions := map[string]func() inertia.Session{
+		"memory": func() inertia.Session { return inertia.NewMemorySession("sid") },
+		"cookie": func() inertia.Session { return cookieSession },
+		"store":  func() inertia.Session { return inertia.NewStoreSession("sid", sessiontest.NewStore()) },
+	}
+
+	for name, newSession := range sessions {
+		t.Run(name, func(t *testing.T) {
+			t.Run("successive flashes are merged", func(t *testing.T) {
+				b := newBrowser(t, newSession())
+
+				w := b.do("POST", func(w http.ResponseWriter, r *http.Request) {
+					require.NoError(t, b.i.Flash(w, r, "success", "Saved"))
+					require.NoError(t, b.i.FlashMultiple(w, r, map[string]any{"notice": "Check your email", "success": "Saved!"}))
+					require.NoError(t, b.i.RenderErrors(w, r, map[string]any{"name": "Name is required"}))
+				})
+				assert.Len(t, w.Result().Cookies(), 1, "one session cookie")
+
+				flash, errors := b.flash()
+				assert.Equal(t, "Saved!", flash["success"])
+				assert.Equal(t, "Check your email", flash["notice"])
+				assert.Equal(t, map[string]any{"name": "Name is required"}, errors)
+
+				flash, _ = b.flash()
+				assert.Empty(t, flash)
+			})
+
+			t.Run("reflash", func(t *testing.T) {
+				b := newBrowser(t, newSession())
+
+				b.do("POST", func(w http.ResponseWriter, r *http.Request) {
+					require.NoError(t, b.i.Flash(w, r, "success", "Saved"))
+				})
+				b.do("GET", func(w http.ResponseWriter, r *http.Request) {
+					require.NoError(t, b.i.Reflash(w, r))
+				})
+
+				flash, _ := b.flash()
+				assert.Equal(t, map[string]any{"success": "Saved"}, flash)
+			})
+
+			t.Run("keep", func(t *testing.T) {
+				b := newBrowser(t, newSession())
+
+				b.do("POST", func(w http.ResponseWriter, r *http.Request) {
+					require.NoError(t, b.i.FlashMultiple(w, r, map[string]any{
+						"success": "Saved",
+						"notice":  "Check your email",
+						"warning": "Almost full",
+					}))
+				})
+				b.do("GET", func(w http.ResponseWriter, r *http.Request) {
+					require.NoError(t, b.i.Flash(w, r, "success", "Saved again"))
+					require.NoError(t, b.i.Keep(w, r, "success", "notice", "missing"))
+				})
+
+				flash, _ := b.flash()
+				assert.Equal(t, map[string]any{"success": "Saved again", "notice": "Check your email"}, flash)
+			})
+		})
+	}
+}
+
+func TestFlash_WithoutMiddleware(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	require.NoError(t, i.Flash(w, r, "success", "Saved"))
+	assert.NoError(t, i.Reflash(w, r))
+	assert.NoError(t, i.Keep(w, r, "success"))
+}
@@ -0,0 +1,186 @@
+// Package sessiontest provides an in-memory inertia.SessionStore and checks
+// for SessionStore implementations, for tests.
+package sessiontest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	inertia "github.com/joetifa2003/inertigo"
+)
+
+// Store is an in-memory inertia.SessionStore.
+type Store struct {
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+type entry struct {
+	value   []byte
+	expires time.Time // zero for no expiry
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{entries: make(map[string]entry)}
+}
+
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := entry{value: value}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	s.entries[key] = e
+	return nil
+}
+
+func (s *Store) GetDel(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.entries, key)
+
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		return nil, nil
+	}
+	return e.value, nil
+}
+
+// Len returns the number of entries, including expired ones not yet read.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+// RunStoreTests checks that store behaves as an inertia.SessionStore. The
+// store should be empty; expiry is left to the caller, since stores measure
+// time differently.
+func RunStoreTests(t *testing.T, store inertia.SessionStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Run("missing key", func(t *testing.T) {
+		value, err := store.GetDel(ctx, "sessiontest:missing")
+		if err != nil {
+			t.Fatalf("GetDel: %v", err)
+		}
+		if value != nil {
+			t.Errorf("GetDel of a missing key = %q, want nil", value)
+		}
+	})
+
+	t.Run("set and get once", func(t *testing.T) {
+		if err := store.Set(ctx, "sessiontest:once", []byte("value"), time.Minute); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+
+		value, err := store.GetDel(ctx, "sessiontest:once")
+		if err != nil {
+			t.Fatalf("GetDel: %v", err)
+		}
+		if string(value) != "value" {
+			t.Errorf("GetDel = %q, want %q", value, "value")
+		}
+
+		value, err = store.GetDel(ctx, "sessiontest:once")
+		if err != nil {
+			t.Fatalf("second GetDel: %v", err)
+		}
+		if value != nil {
+			t.Errorf("second GetDel = %q, want nil", value)
+		}
+	})
+
+	t.Run("set replaces", func(t *testing.T) {
+		for _, v := range []string{"first", "second"} {
+			if err := store.Set(ctx, "sessiontest:replace", []byte(v), time.Minute); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+		}
+
+		value, err := store.GetDel(ctx, "sessiontest:replace")
+		if err != nil {
+			t.Fatalf("GetDel: %v", err)
+		}
+		if string(value) != "second" {
+			t.Errorf("GetDel = %q, want %q", value, "second")
+		}
+	})
+
+	t.Run("keys are separate", func(t *testing.T) {
+		if err := store.Set(ctx, "sessiontest:a", []byte("a"), time.Minute); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		if err := store.Set(ctx, "sessiontest:b", []byte("b"), time.Minute); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+
+		for _, key := range []string{"a", "b"} {
+			value, err := store.GetDel(ctx, "sessiontest:"+key)
+			if err != nil {
+				t.Fatalf("GetDel: %v", err)
+			}
+			if string(value) != key {
+				t.Errorf("GetDel(%q) = %q, want %q", key, value, key)
+			}
+		}
+	})
+
+	t.Run("no ttl keeps the value", func(t *testing.T) {
+		for _, ttl := range []time.Duration{0, -time.Minute} {
+			if err := store.Set(ctx, "sessiontest:nottl", []byte("value"), ttl); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+
+			value, err := store.GetDel(ctx, "sessiontest:nottl")
+			if err != nil {
+				t.Fatalf("GetDel: %v", err)
+			}
+			if string(value) != "value" {
+				t.Errorf("GetDel after Set with ttl %v = %q, want %q", ttl, value, "value")
+			}
+		}
+	})
+
+	t.Run("concurrent GetDel returns the value once", func(t *testing.T) {
+		if err := store.Set(ctx, "sessiontest:race", []byte("value"), time.Minute); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+
+		const readers = 8
+		results := make(chan []byte, readers)
+		var wg sync.WaitGroup
+		for range readers {
+			wg.Go(func() {
+				value, err := store.GetDel(ctx, "sessiontest:race")
+				if err != nil {
+					t.Errorf("GetDel: %v", err)
+				}
+				results <- value
+			})
+		}
+		wg.Wait()
+		close(results)
+
+		found := 0
+		for value := range results {
+			if value != nil {
+				found++
+			}
+		}
+		if found != 1 {
+			t.Errorf("value returned %d times, want 1", found)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package sidecar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	inertia "github.com/joetifa2003/inertigo"
+)
+
+// DefaultURL is the address Inertia's SSR server listens on by default.
+const DefaultURL = "http://127.0.0.1:13714"
+
+// HTTPEngine renders pages by calling an Inertia SSR server over HTTP.
+type HTTPEngine struct {
+	url    string
+	client *http.Client
+}
+
+type httpConfig struct {
+	client *http.Client
+}
+
+// HTTPOption configures an HTTPEngine.
+type HTTPOption func(config *httpConfig)
+
+// WithHTTPClient sets the client used to call the SSR server.
+// Default: http.DefaultClient
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(config *httpConfig) {
+		config.client = client
+	}
+}
+
+// NewHTTP creates an engine that renders pages by POSTing them to
+// serverURL + "/render".
+func NewHTTP(serverURL string, options ...HTTPOption) (*HTTPEngine, error) {
+	config := httpConfig{
+		client: http.DefaultClient,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	if _, err := url.ParseRequestURI(serverURL); err != nil {
+		return nil, fmt.Errorf("invalid ssr server url: %w", err)
+	}
+
+	return &HTTPEngine{
+		url:    strings.TrimSuffix(serverURL, "/"),
+		client: config.client,
+	}, nil
+}
+
+func (e *HTTPEngine) Name() string { return "sidecar-http" }
+
+func (e *HTTPEngine) Render(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+	pageJSON, err := json.Marshal(page)
+	if err != nil {
+		return inertia.RenderedPage{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url+"/render", bytes.NewReader(pageJSON))
+	if err != nil {
+		return inertia.RenderedPage{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return inertia.RenderedPage{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return inertia.RenderedPage{}, fmt.Errorf("ssr server responded with %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var result inertia.RenderedPage
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return inertia.RenderedPage{}, err
+	}
+
+	return result, nil
+}
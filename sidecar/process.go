@@ -0,0 +1,476 @@
+package sidecar
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	inertia "github.com/joetifa2003/inertigo"
+)
+
+//go:embed worker.mjs
+var workerScript []byte
+
+var errWorkerExited = errors.New("sidecar: worker process exited")
+
+// ProcessEngine renders pages in a pool of JavaScript worker processes that
+// it spawns and supervises. Each worker renders one page at a time; crashed
+// or hung workers are killed and restarted.
+type ProcessEngine struct {
+	config    processConfig
+	script    string
+	ownScript bool
+
+	jobs      chan *job
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+type processConfig struct {
+	command      string
+	args         []string
+	bundlePath   string
+	script       string
+	workers      int
+	env          []string
+	dir          string
+	stderr       io.Writer
+	logger       inertia.Logger
+	startTimeout time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+}
+
+// ProcessOption configures a ProcessEngine.
+type ProcessOption func(config *processConfig) error
+
+// WithCommand sets the JavaScript runtime to spawn, e.g. "node" or "bun",
+// plus any arguments placed before the worker script.
+// Default: "node"
+func WithCommand(name string, args ...string) ProcessOption {
+	return func(config *processConfig) error {
+		config.command = name
+		config.args = args
+		return nil
+	}
+}
+
+// WithBundlePath sets the path of the SSR bundle. The bundle must export a
+// renderPage function that takes a page object and returns {head, body}.
+func WithBundlePath(path string) ProcessOption {
+	return func(config *processConfig) error {
+		config.bundlePath = path
+		return nil
+	}
+}
+
+// WithWorkerScript replaces the built-in worker script with a custom one
+// that speaks the same stdin/stdout protocol.
+func WithWorkerScript(path string) ProcessOption {
+	return func(config *processConfig) error {
+		config.script = path
+		return nil
+	}
+}
+
+// WithWorkers sets the number of worker processes.
+// Default: 1
+func WithWorkers(n int) ProcessOption {
+	return func(config *processConfig) error {
+		if n <= 0 {
+			return fmt.Errorf("worker count must be positive, got %d", n)
+		}
+		config.workers = n
+		return nil
+	}
+}
+
+// WithEnv adds environment variables ("KEY=value") to the worker processes.
+func WithEnv(env ...string) ProcessOption {
+	return func(config *processConfig) error {
+		config.env = append(config.env, env...)
+		return nil
+	}
+}
+
+// WithDir sets the working directory of the worker processes.
+func WithDir(dir string) ProcessOption {
+	return func(config *processConfig) error {
+		config.dir = dir
+		return nil
+	}
+}
+
+// WithStderr sets where the workers' stderr, including console output,
+// is written.
+// Default: os.Stderr
+func WithStderr(w io.Writer) ProcessOption {
+	return func(config *processConfig) error {
+		config.stderr = w
+		return nil
+	}
+}
+
+// WithLogger sets the logger used to report worker crashes and restarts.
+func WithLogger(logger inertia.Logger) ProcessOption {
+	return func(config *processConfig) error {
+		config.logger = logger
+		return nil
+	}
+}
+
+// WithStartTimeout sets how long a worker may take to load the bundle.
+// Default: 10s
+func WithStartTimeout(timeout time.Duration) ProcessOption {
+	return func(config *processConfig) error {
+		config.startTimeout = timeout
+		return nil
+	}
+}
+
+// WithRestartBackoff sets the delay between restarts of a worker that keeps
+// failing. The delay doubles after every failure, up to max.
+// Default: 100ms to 5s
+func WithRestartBackoff(min, max time.Duration) ProcessOption {
+	return func(config *processConfig) error {
+		config.minBackoff = min
+		config.maxBackoff = max
+		return nil
+	}
+}
+
+// NewProcess starts the worker pool. It returns an error if the first
+// start of any worker fails, e.g. because the runtime is not installed or
+// the bundle cannot be loaded.
+func NewProcess(options ...ProcessOption) (*ProcessEngine, error) {
+	config := processConfig{
+		command:      "node",
+		workers:      1,
+		stderr:       os.Stderr,
+		startTimeout: 10 * time.Second,
+		minBackoff:   100 * time.Millisecond,
+		maxBackoff:   5 * time.Second,
+	}
+
+	for _, option := range options {
+		err := option(&config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.bundlePath == "" && config.script == "" {
+		return nil, errors.New("sidecar: bundle path is required")
+	}
+
+	if config.logger == nil {
+		config.logger = slog.New(slog.DiscardHandler)
+	}
+
+	e := &ProcessEngine{
+		config: config,
+		script: config.script,
+		jobs:   make(chan *job),
+		done:   make(chan struct{}),
+	}
+
+	if e.script == "" {
+		f, err := os.CreateTemp("", "inertigo-sidecar-*.mjs")
+		if err != nil {
+			return nil, err
+		}
+		_, err = f.Write(workerScript)
+		f.Close()
+		if err != nil {
+			os.Remove(f.Name())
+			return nil, err
+		}
+		e.script = f.Name()
+		e.ownScript = true
+	}
+
+	procs := make([]*process, 0, config.workers)
+	for range config.workers {
+		p, err := e.start()
+		if err != nil {
+			for _, p := range procs {
+				p.kill()
+			}
+			e.removeScript()
+			return nil, err
+		}
+		procs = append(procs, p)
+	}
+
+	for id, p := range procs {
+		e.wg.Add(1)
+		go e.supervise(id, p)
+	}
+
+	return e, nil
+}
+
+func (e *ProcessEngine) Name() string { return "sidecar-process" }
+
+// Render sends the page to the next free worker. If ctx is done while the
+// worker is rendering, the worker is killed and restarted.
+func (e *ProcessEngine) Render(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+	j := &job{
+		ctx:    ctx,
+		page:   page,
+		result: make(chan jobResult, 1),
+	}
+
+	select {
+	case e.jobs <- j:
+	case <-ctx.Done():
+		return inertia.RenderedPage{}, ctx.Err()
+	case <-e.done:
+		return inertia.RenderedPage{}, ErrClosed
+	}
+
+	res := <-j.result
+	return res.page, res.err
+}
+
+// Close stops all workers and waits for them to exit.
+func (e *ProcessEngine) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.done)
+		e.wg.Wait()
+		e.removeScript()
+	})
+	return nil
+}
+
+func (e *ProcessEngine) removeScript() {
+	if e.ownScript {
+		os.Remove(e.script)
+	}
+}
+
+type job struct {
+	ctx    context.Context
+	page   inertia.PageObject
+	result chan jobResult
+}
+
+type jobResult struct {
+	page inertia.RenderedPage
+	err  error
+}
+
+// process is a running worker.
+type process struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan []byte // closed once stdout is drained and the process exited
+	nextID uint64
+}
+
+func (p *process) kill() {
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	for range p.lines {
+	}
+}
+
+// start spawns a worker and waits until it reports that its bundle is loaded.
+func (e *ProcessEngine) start() (*process, error) {
+	args := append([]string{}, e.config.args...)
+	args = append(args, e.script)
+	if e.config.bundlePath != "" {
+		args = append(args, e.config.bundlePath)
+	}
+
+	cmd := exec.Command(e.config.command, args...)
+	cmd.Env = append(os.Environ(), e.config.env...)
+	cmd.Dir = e.config.dir
+	cmd.Stderr = e.config.stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start sidecar worker: %w", err)
+	}
+
+	p := &process{
+		cmd:   cmd,
+		stdin: stdin,
+		lines: make(chan []byte),
+	}
+
+	go func() {
+		defer close(p.lines)
+
+		reader := bufio.NewReader(stdout)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				p.lines <- line
+			}
+			if err != nil {
+				break
+			}
+		}
+		cmd.Wait()
+	}()
+
+	timer := time.NewTimer(e.config.startTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case line, ok := <-p.lines:
+			if !ok {
+				return nil, fmt.Errorf("sidecar worker exited before it was ready: %s", cmd.ProcessState)
+			}
+			var resp Response
+			if json.Unmarshal(line, &resp) == nil && resp.Ready {
+				return p, nil
+			}
+		case <-timer.C:
+			p.kill()
+			return nil, errors.New("sidecar worker did not become ready in time")
+		}
+	}
+}
+
+// supervise serves jobs with p and restarts the worker whenever it dies,
+// backing off while it keeps failing.
+func (e *ProcessEngine) supervise(id int, p *process) {
+	defer e.wg.Done()
+
+	ctx := context.Background()
+	backoff := e.config.minBackoff
+
+	for {
+		if p != nil {
+			if e.serve(p) {
+				backoff = e.config.minBackoff
+			}
+			p.kill()
+			p = nil
+
+			select {
+			case <-e.done:
+				return
+			default:
+			}
+
+			e.config.logger.LogAttrs(ctx, slog.LevelWarn, "sidecar worker stopped, restarting",
+				slog.Int("worker", id),
+				slog.String("backoff", backoff.String()),
+			)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-e.done:
+			return
+		}
+
+		var err error
+		p, err = e.start()
+		if err != nil {
+			e.config.logger.LogAttrs(ctx, slog.LevelError, "sidecar worker failed to start",
+				slog.Int("worker", id),
+				slog.String("error", err.Error()),
+			)
+			backoff = min(backoff*2, e.config.maxBackoff)
+		}
+	}
+}
+
+// serve handles jobs until the process must be replaced or the engine is
+// closed. It reports whether at least one render succeeded.
+func (e *ProcessEngine) serve(p *process) (healthy bool) {
+	for {
+		select {
+		case <-e.done:
+			return healthy
+		case _, ok := <-p.lines:
+			// Stray output between renders is ignored.
+			if !ok {
+				return healthy
+			}
+		case j := <-e.jobs:
+			ok, rendered := e.handle(p, j)
+			healthy = healthy || rendered
+			if !ok {
+				return healthy
+			}
+		}
+	}
+}
+
+// handle runs a single job. It reports whether the process can be reused
+// and whether the render succeeded.
+func (e *ProcessEngine) handle(p *process, j *job) (reusable bool, rendered bool) {
+	if err := j.ctx.Err(); err != nil {
+		j.result <- jobResult{err: err}
+		return true, false
+	}
+
+	p.nextID++
+	id := p.nextID
+
+	data, err := json.Marshal(Request{ID: id, Page: j.page})
+	if err != nil {
+		j.result <- jobResult{err: err}
+		return true, false
+	}
+
+	if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+		j.result <- jobResult{err: fmt.Errorf("%w: %w", errWorkerExited, err)}
+		return false, false
+	}
+
+	for {
+		select {
+		case line, ok := <-p.lines:
+			if !ok {
+				j.result <- jobResult{err: errWorkerExited}
+				return false, false
+			}
+
+			var resp Response
+			if json.Unmarshal(line, &resp) != nil || resp.ID != id {
+				continue
+			}
+
+			if resp.Error != "" {
+				j.result <- jobResult{err: fmt.Errorf("sidecar render failed: %s", resp.Error)}
+				return true, false
+			}
+
+			j.result <- jobResult{page: inertia.RenderedPage{Head: resp.Head, Body: resp.Body}}
+			return true, true
+		case <-j.ctx.Done():
+			// Workers render one page at a time, so a render that is still
+			// running would hold up every request behind it.
+			j.result <- jobResult{err: j.ctx.Err()}
+			return false, false
+		case <-e.done:
+			j.result <- jobResult{err: ErrClosed}
+			return false, false
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Package sidecar provides SSR engines that render pages in an external
+// JavaScript process such as Node.js or Bun.
+//
+// HTTPEngine talks to a long-lived SSR server using Inertia's standard
+// protocol: the page object is POSTed as JSON to /render and the server
+// answers with {"head": [...], "body": "..."}.
+//
+// ProcessEngine spawns and supervises a pool of worker processes itself and
+// talks to them over stdin/stdout using newline-delimited JSON, see Request
+// and Response.
+package sidecar
+
+import (
+	"errors"
+
+	inertia "github.com/joetifa2003/inertigo"
+)
+
+// ErrClosed is returned when rendering with an engine that has been closed.
+var ErrClosed = errors.New("sidecar: engine closed")
+
+// Request is a render request written to a worker's stdin as a single line
+// of JSON.
+type Request struct {
+	ID   uint64             `json:"id"`
+	Page inertia.PageObject `json:"page"`
+}
+
+// Response is written by a worker to its stdout as a single line of JSON.
+// Before handling requests, a worker writes {"ready": true} once its bundle
+// is loaded.
+type Response struct {
+	ID    uint64   `json:"id"`
+	Ready bool     `json:"ready,omitempty"`
+	Head  []string `json:"head,omitempty"`
+	Body  string   `json:"body,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
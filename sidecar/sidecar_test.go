@@ -0,0 +1,215 @@
+package sidecar_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/sidecar"
+	"github.com/joetifa2003/inertigo/sidecar/sidecartest"
+)
+
+const fakeWorkerEnv = "SIDECAR_FAKE_WORKER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeWorkerEnv) == "1" {
+		sidecartest.ServeWorker(os.Stdin, os.Stdout, fakeRender)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeRender is the render function of the fake worker process. The
+// component name selects how it behaves.
+func fakeRender(page inertia.PageObject) (inertia.RenderedPage, error) {
+	switch page.Component {
+	case "crash":
+		os.Exit(1)
+	case "hang":
+		select {}
+	case "error":
+		return inertia.RenderedPage{}, errors.New("component threw")
+	}
+
+	return inertia.RenderedPage{
+		Head: []string{fmt.Sprintf("<meta name=pid content=%d>", os.Getpid())},
+		Body: "<div>" + page.Component + "</div>",
+	}, nil
+}
+
+func newFakeProcessEngine(t *testing.T, options ...sidecar.ProcessOption) *sidecar.ProcessEngine {
+	t.Helper()
+
+	options = append([]sidecar.ProcessOption{
+		sidecar.WithCommand(os.Args[0]),
+		sidecar.WithWorkerScript("unused"),
+		sidecar.WithEnv(fakeWorkerEnv + "=1"),
+		sidecar.WithStderr(io.Discard),
+		sidecar.WithRestartBackoff(time.Millisecond, 10*time.Millisecond),
+	}, options...)
+
+	engine, err := sidecar.NewProcess(options...)
+	require.NoError(t, err)
+	t.Cleanup(func() { engine.Close() })
+
+	return engine
+}
+
+func TestHTTPEngine_Render(t *testing.T) {
+	server := sidecartest.NewServer(func(page inertia.PageObject) (inertia.RenderedPage, error) {
+		if page.Component == "error" {
+			return inertia.RenderedPage{}, errors.New("component threw")
+		}
+		return inertia.RenderedPage{Head: []string{"<title>Home</title>"}, Body: "<div>" + page.Component + "</div>"}, nil
+	})
+	defer server.Close()
+
+	engine, err := sidecar.NewHTTP(server.URL)
+	require.NoError(t, err)
+
+	t.Run("renders the page", func(t *testing.T) {
+		page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"<title>Home</title>"}, page.Head)
+		assert.Equal(t, "<div>Home</div>", page.Body)
+	})
+
+	t.Run("returns server errors", func(t *testing.T) {
+		_, err := engine.Render(context.Background(), inertia.PageObject{Component: "error"})
+		assert.ErrorContains(t, err, "component threw")
+	})
+}
+
+func TestNewHTTP_InvalidURL(t *testing.T) {
+	_, err := sidecar.NewHTTP("not a url")
+	assert.Error(t, err)
+}
+
+func TestProcessEngine_Render(t *testing.T) {
+	engine := newFakeProcessEngine(t)
+
+	page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	require.NoError(t, err)
+	assert.Equal(t, "<div>Home</div>", page.Body)
+
+	_, err = engine.Render(context.Background(), inertia.PageObject{Component: "error"})
+	assert.ErrorContains(t, err, "component threw")
+
+	// A render error does not take the worker down.
+	again, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	require.NoError(t, err)
+	assert.Equal(t, page.Head, again.Head)
+}
+
+func TestProcessEngine_RestartsCrashedWorker(t *testing.T) {
+	engine := newFakeProcessEngine(t)
+
+	before, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	require.NoError(t, err)
+
+	_, err = engine.Render(context.Background(), inertia.PageObject{Component: "crash"})
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	after, err := engine.Render(ctx, inertia.PageObject{Component: "Home"})
+	require.NoError(t, err)
+	assert.NotEqual(t, before.Head, after.Head, "expected a new worker process")
+}
+
+func TestProcessEngine_KillsHungWorker(t *testing.T) {
+	engine := newFakeProcessEngine(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := engine.Render(ctx, inertia.PageObject{Component: "hang"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	page, err := engine.Render(ctx, inertia.PageObject{Component: "Home"})
+	require.NoError(t, err)
+	assert.Equal(t, "<div>Home</div>", page.Body)
+}
+
+func TestProcessEngine_Workers(t *testing.T) {
+	engine := newFakeProcessEngine(t, sidecar.WithWorkers(3))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	pids := map[string]bool{}
+
+	for range 30 {
+		wg.Go(func() {
+			page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+			assert.NoError(t, err)
+
+			mu.Lock()
+			pids[page.Head[0]] = true
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, len(pids), 3)
+}
+
+func TestProcessEngine_Close(t *testing.T) {
+	engine := newFakeProcessEngine(t)
+	require.NoError(t, engine.Close())
+
+	_, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+	assert.ErrorIs(t, err, sidecar.ErrClosed)
+}
+
+func TestNewProcess_StartFailure(t *testing.T) {
+	_, err := sidecar.NewProcess(
+		sidecar.WithCommand(filepath.Join(t.TempDir(), "missing-runtime")),
+		sidecar.WithBundlePath("ssr.js"),
+	)
+	assert.Error(t, err)
+}
+
+func TestProcessEngine_Node(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not installed")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ssr.mjs")
+	err := os.WriteFile(bundle, []byte(`
+		export async function renderPage(page) {
+			console.log("rendering", page.component);
+			return { head: ["<title>" + page.component + "</title>"], body: "<div>" + page.props.message + "</div>" };
+		}
+	`), 0o644)
+	require.NoError(t, err)
+
+	engine, err := sidecar.NewProcess(
+		sidecar.WithBundlePath(bundle),
+		sidecar.WithStderr(io.Discard),
+	)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	page, err := engine.Render(context.Background(), inertia.PageObject{
+		Component: "Home",
+		Props:     map[string]any{"message": "hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"<title>Home</title>"}, page.Head)
+	assert.Equal(t, "<div>hello</div>", page.Body)
+}
@@ -0,0 +1,88 @@
+// Package sidecartest provides in-process fakes of the sidecar SSR protocols
+// for tests.
+package sidecartest
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/sidecar"
+)
+
+// RenderFunc renders a page the way an SSR bundle's renderPage would.
+type RenderFunc func(page inertia.PageObject) (inertia.RenderedPage, error)
+
+// NewServer starts an HTTP server that speaks Inertia's SSR server protocol
+// using render. The caller must Close it.
+func NewServer(render RenderFunc) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/render" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var page inertia.PageObject
+		if err := json.NewDecoder(r.Body).Decode(&page); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rendered, err := render(page)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rendered)
+	}))
+}
+
+// ServeWorker speaks the worker side of the sidecar stdin/stdout protocol,
+// rendering every request with render until r is exhausted.
+//
+// It is meant to be run from a test binary that re-executes itself as the
+// worker command:
+//
+//	func TestMain(m *testing.M) {
+//		if os.Getenv("FAKE_SSR_WORKER") == "1" {
+//			sidecartest.ServeWorker(os.Stdin, os.Stdout, render)
+//			os.Exit(0)
+//		}
+//		os.Exit(m.Run())
+//	}
+func ServeWorker(r io.Reader, w io.Writer, render RenderFunc) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(sidecar.Response{Ready: true}); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		var req sidecar.Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			return err
+		}
+
+		resp := sidecar.Response{ID: req.ID}
+		rendered, err := render(req.Page)
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Head = rendered.Head
+			resp.Body = rendered.Body
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
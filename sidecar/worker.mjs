@@ -0,0 +1,32 @@
+// inertigo sidecar worker: loads the SSR bundle given as the first argument
+// and renders pages read from stdin, one JSON request per line.
+import { createInterface } from "node:readline";
+import { pathToFileURL } from "node:url";
+
+const write = (msg) => process.stdout.write(JSON.stringify(msg) + "\n");
+
+// stdout carries the protocol, so route console output to stderr.
+for (const method of ["log", "info", "debug"]) {
+  console[method] = (...args) => console.error(...args);
+}
+
+const bundle = await import(pathToFileURL(process.argv[2]).href);
+const renderPage = bundle.renderPage ?? bundle.default?.renderPage ?? bundle.default;
+if (typeof renderPage !== "function") {
+  throw new Error("ssr bundle does not export a renderPage function");
+}
+
+write({ id: 0, ready: true });
+
+const lines = createInterface({ input: process.stdin, crlfDelay: Infinity });
+for await (const line of lines) {
+  if (!line) continue;
+
+  const { id, page } = JSON.parse(line);
+  try {
+    const result = await renderPage(page);
+    write({ id, head: result?.head ?? [], body: result?.body ?? "" });
+  } catch (err) {
+    write({ id, error: String(err?.stack ?? err) });
+  }
+}
@@ -1,5 +1,7 @@
 package inertia
 
+import "context"
+
 // RenderedPage represents the result of server-side rendering.
 type RenderedPage struct {
 	// Head contains HTML strings to be injected into the <head> section.
@@ -14,5 +16,6 @@ type SSREngine interface {
 	// Name returns a human-readable name for the SSR engine.
 	Name() string
 	// Render renders a PageObject and returns the rendered HTML.
-	Render(pageObject PageObject) (RenderedPage, error)
+	// Implementations should return promptly once ctx is done.
+	Render(ctx context.Context, pageObject PageObject) (RenderedPage, error)
 }
@@ -0,0 +1,153 @@
+package inertia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSSRCircuitOpen is returned instead of rendering while the SSR circuit
+// breaker is open. Full page loads are served client-side rendered.
+var ErrSSRCircuitOpen = errors.New("inertia: ssr circuit breaker is open")
+
+// SSRCircuitState is the state of the SSR circuit breaker.
+type SSRCircuitState string
+
+const (
+	// SSRCircuitClosed means SSR is healthy and every render goes to the engine.
+	SSRCircuitClosed SSRCircuitState = "closed"
+	// SSRCircuitOpen means SSR is skipped until the cool-down period ends.
+	SSRCircuitOpen SSRCircuitState = "open"
+	// SSRCircuitHalfOpen means a single probe render is in flight.
+	SSRCircuitHalfOpen SSRCircuitState = "half-open"
+)
+
+// SSRHealth is a snapshot of the SSR circuit breaker, suitable for
+// serving from a health endpoint.
+type SSRHealth struct {
+	// State is the current breaker state.
+	State SSRCircuitState `json:"state"`
+	// ConsecutiveFailures is the number of failed renders since the last success.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// TotalFailures is the number of failed renders since startup.
+	TotalFailures uint64 `json:"totalFailures"`
+	// LastError is the error of the most recent failed render.
+	LastError string `json:"lastError,omitempty"`
+	// LastFailureAt is when the most recent render failed.
+	LastFailureAt time.Time `json:"lastFailureAt,omitzero"`
+	// OpenedAt is when the breaker last opened.
+	OpenedAt time.Time `json:"openedAt,omitzero"`
+	// RetryAt is when the breaker will let a probe render through.
+	// It is only set while the breaker is open.
+	RetryAt time.Time `json:"retryAt,omitzero"`
+}
+
+// ssrCircuitBreaker wraps an SSREngine and stops calling it after too many
+// consecutive failures. Once the cool-down period has passed, a single
+// probe render is let through: if it succeeds the circuit closes again,
+// otherwise it stays open for another cool-down period.
+type ssrCircuitBreaker struct {
+	engine    SSREngine
+	threshold int
+	cooldown  time.Duration
+
+	mu     sync.Mutex
+	health SSRHealth
+}
+
+func newSSRCircuitBreaker(threshold int, cooldown time.Duration) *ssrCircuitBreaker {
+	return &ssrCircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		health:    SSRHealth{State: SSRCircuitClosed},
+	}
+}
+
+func (b *ssrCircuitBreaker) Name() string { return b.engine.Name() }
+
+func (b *ssrCircuitBreaker) Render(ctx context.Context, page PageObject) (RenderedPage, error) {
+	if err := b.acquire(); err != nil {
+		return RenderedPage{}, err
+	}
+
+	// A panicking engine counts as a failure, or a half-open probe would
+	// never finish and keep the breaker half-open for good.
+	defer func() {
+		if v := recover(); v != nil {
+			b.release(ctx, fmt.Errorf("ssr render panicked: %v", v))
+			panic(v)
+		}
+	}()
+
+	renderedPage, err := b.engine.Render(ctx, page)
+	b.release(ctx, err)
+
+	return renderedPage, err
+}
+
+// acquire reports whether a render may go to the engine, moving an open
+// breaker whose cool-down has passed to half-open.
+func (b *ssrCircuitBreaker) acquire() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.health.State {
+	case SSRCircuitOpen:
+		if time.Now().Before(b.health.RetryAt) {
+			return ErrSSRCircuitOpen
+		}
+		b.health.State = SSRCircuitHalfOpen
+		b.health.RetryAt = time.Time{}
+		return nil
+	case SSRCircuitHalfOpen:
+		// Only the probe render goes through.
+		return ErrSSRCircuitOpen
+	default:
+		return nil
+	}
+}
+
+// release records the outcome of a render started by acquire.
+func (b *ssrCircuitBreaker) release(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The client went away, which says nothing about the engine's health.
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		if b.health.State == SSRCircuitHalfOpen {
+			b.open(time.Now())
+		}
+		return
+	}
+
+	if err == nil {
+		b.health.State = SSRCircuitClosed
+		b.health.ConsecutiveFailures = 0
+		return
+	}
+
+	now := time.Now()
+	b.health.ConsecutiveFailures++
+	b.health.TotalFailures++
+	b.health.LastError = err.Error()
+	b.health.LastFailureAt = now
+
+	if b.health.State == SSRCircuitHalfOpen || b.health.ConsecutiveFailures >= b.threshold {
+		b.open(now)
+	}
+}
+
+func (b *ssrCircuitBreaker) open(now time.Time) {
+	b.health.State = SSRCircuitOpen
+	b.health.OpenedAt = now
+	b.health.RetryAt = now.Add(b.cooldown)
+}
+
+func (b *ssrCircuitBreaker) snapshot() SSRHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.health
+}
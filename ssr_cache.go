@@ -0,0 +1,141 @@
+package inertia
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// ssrCache is a size-bounded LRU of rendered pages with an optional TTL.
+type ssrCache struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+}
+
+type ssrCacheEntry struct {
+	key       string
+	component string
+	page      RenderedPage
+	expiresAt time.Time
+}
+
+func newSSRCache(maxEntries int, ttl time.Duration) *ssrCache {
+	return &ssrCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// pageKey derives a cache key from everything that ends up in the rendered
+// HTML, so two pages share an entry only if they would render the same.
+func (c *ssrCache) pageKey(page *PageObject) (string, error) {
+	data, err := json.Marshal(page)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return "page:" + hex.EncodeToString(sum[:]), nil
+}
+
+// customKey namespaces a caller-supplied key by asset version, so a deploy
+// never serves pages rendered against old assets.
+func (c *ssrCache) customKey(version, key string) string {
+	return "key:" + version + "\x00" + key
+}
+
+func (c *ssrCache) get(key string) (RenderedPage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return RenderedPage{}, false
+	}
+
+	entry := el.Value.(*ssrCacheEntry)
+	if !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+		c.remove(el)
+		return RenderedPage{}, false
+	}
+
+	c.lru.MoveToFront(el)
+	return entry.page, true
+}
+
+func (c *ssrCache) set(key, component string, page RenderedPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*ssrCacheEntry)
+		entry.page = page
+		entry.expiresAt = expiresAt
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&ssrCacheEntry{
+		key:       key,
+		component: component,
+		page:      page,
+		expiresAt: expiresAt,
+	})
+
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// invalidate removes every entry rendered for one of components.
+func (c *ssrCache) invalidate(components []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		for _, component := range components {
+			if el.Value.(*ssrCacheEntry).component == component {
+				c.remove(el)
+				break
+			}
+		}
+		el = next
+	}
+}
+
+func (c *ssrCache) invalidateKey(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+}
+
+func (c *ssrCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+	c.lru.Init()
+}
+
+func (c *ssrCache) remove(el *list.Element) {
+	delete(c.entries, el.Value.(*ssrCacheEntry).key)
+	c.lru.Remove(el)
+}
@@ -0,0 +1,563 @@
+package inertia_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+// fakeSSREngine is an SSREngine whose behaviour is controlled by render.
+type fakeSSREngine struct {
+	render func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error)
+}
+
+func (e *fakeSSREngine) Name() string { return "fake" }
+
+func (e *fakeSSREngine) Render(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+	return e.render(ctx, page)
+}
+
+func newSSRTestInertia(t *testing.T, engine inertia.SSREngine, options ...inertia.InertiaOption) *inertia.Inertia {
+	t.Helper()
+
+	bundler, err := vite.New(nil)
+	require.NoError(t, err)
+
+	rootFS := fstest.MapFS{
+		"index.html": &fstest.MapFile{
+			Data: []byte(`<html><head>{{ .InertiaHead }}</head><body>{{ .InertiaBody }}</body></html>`),
+		},
+	}
+
+	options = append([]inertia.InertiaOption{
+		inertia.WithRootHtmlPathFS(rootFS, "index.html"),
+		inertia.WithSSR(true, func() (inertia.SSREngine, error) { return engine, nil }),
+	}, options...)
+
+	i, err := inertia.New(bundler, options...)
+	require.NoError(t, err)
+
+	return i
+}
+
+func TestRender_SSR(t *testing.T) {
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			return inertia.RenderedPage{
+				Head: []string{"<title>" + page.Component + "</title>"},
+				Body: `<div id="app">rendered</div>`,
+			}, nil
+		},
+	}
+	i := newSSRTestInertia(t, engine)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	err := i.Render(w, req, "TestComponent", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, `<html><head><title>TestComponent</title></head><body><div id="app">rendered</div></body></html>`, w.Body.String())
+}
+
+func TestRender_SSRTimeout(t *testing.T) {
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			<-ctx.Done()
+			return inertia.RenderedPage{}, ctx.Err()
+		},
+	}
+	i := newSSRTestInertia(t, engine, inertia.WithSSRTimeout(10*time.Millisecond))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	err := i.Render(w, req, "TestComponent", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRender_SSRRequestCancelled(t *testing.T) {
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			<-ctx.Done()
+			return inertia.RenderedPage{}, ctx.Err()
+		},
+	}
+	i := newSSRTestInertia(t, engine)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	err := i.Render(w, req, "TestComponent", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRender_SSRFallback(t *testing.T) {
+	errBroken := errors.New("broken bundle")
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			return inertia.RenderedPage{}, errBroken
+		},
+	}
+
+	t.Run("returns the error when fallback is disabled", func(t *testing.T) {
+		i := newSSRTestInertia(t, engine)
+
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+
+		err := i.Render(w, req, "TestComponent", nil)
+		assert.ErrorIs(t, err, errBroken)
+	})
+
+	t.Run("renders the client-side body when fallback is enabled", func(t *testing.T) {
+		var reported error
+		var reportedComponent string
+		i := newSSRTestInertia(t, engine,
+			inertia.WithSSRFallback(true),
+			inertia.WithSSRErrorHandler(func(r *http.Request, page *inertia.PageObject, err error) {
+				reported = err
+				reportedComponent = page.Component
+			}),
+		)
+
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+
+		err := i.Render(w, req, "TestComponent", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `<div id="app" data-page="`)
+		assert.Contains(t, w.Body.String(), `TestComponent`)
+		assert.ErrorIs(t, reported, errBroken)
+		assert.Equal(t, "TestComponent", reportedComponent)
+	})
+
+	t.Run("falls back on timeout", func(t *testing.T) {
+		engine := &fakeSSREngine{
+			render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+				<-ctx.Done()
+				return inertia.RenderedPage{}, ctx.Err()
+			},
+		}
+		i := newSSRTestInertia(t, engine,
+			inertia.WithSSRFallback(true),
+			inertia.WithSSRTimeout(10*time.Millisecond),
+		)
+
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+
+		err := i.Render(w, req, "TestComponent", nil)
+		require.NoError(t, err)
+		assert.Contains(t, w.Body.String(), `<div id="app" data-page="`)
+	})
+
+	t.Run("falls back when the engine cannot be created", func(t *testing.T) {
+		bundler, err := vite.New(nil)
+		require.NoError(t, err)
+
+		i, err := inertia.New(bundler,
+			inertia.WithRootHtmlPathFS(fstest.MapFS{
+				"index.html": &fstest.MapFile{Data: []byte(`{{ .InertiaBody }}`)},
+			}, "index.html"),
+			inertia.WithSSR(true, func() (inertia.SSREngine, error) { return nil, errBroken }),
+			inertia.WithSSRFallback(true),
+		)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+
+		err = i.Render(w, req, "TestComponent", nil)
+		require.NoError(t, err)
+		assert.Contains(t, w.Body.String(), `<div id="app" data-page="`)
+	})
+}
+
+func TestRender_SSRCircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	var failing atomic.Bool
+	failing.Store(true)
+
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			calls.Add(1)
+			if failing.Load() {
+				return inertia.RenderedPage{}, errors.New("broken bundle")
+			}
+			return inertia.RenderedPage{Body: "<div>ssr</div>"}, nil
+		},
+	}
+	i := newSSRTestInertia(t, engine,
+		inertia.WithSSRFallback(true),
+		inertia.WithSSRCircuitBreaker(2, 50*time.Millisecond),
+	)
+
+	render := func() string {
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		require.NoError(t, i.Render(w, req, "TestComponent", nil))
+		return w.Body.String()
+	}
+
+	assert.Equal(t, inertia.SSRCircuitClosed, i.SSRHealth().State)
+
+	render()
+	render()
+	health := i.SSRHealth()
+	assert.Equal(t, inertia.SSRCircuitOpen, health.State)
+	assert.Equal(t, 2, health.ConsecutiveFailures)
+	assert.Equal(t, "broken bundle", health.LastError)
+	assert.False(t, health.RetryAt.IsZero())
+
+	// While open the engine is not called and pages are client-side rendered.
+	body := render()
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Contains(t, body, `data-page="`)
+
+	// After the cool-down a successful probe closes the circuit.
+	failing.Store(false)
+	time.Sleep(60 * time.Millisecond)
+
+	body = render()
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Contains(t, body, "<div>ssr</div>")
+
+	health = i.SSRHealth()
+	assert.Equal(t, inertia.SSRCircuitClosed, health.State)
+	assert.Equal(t, 0, health.ConsecutiveFailures)
+	assert.Equal(t, uint64(2), health.TotalFailures)
+}
+
+func TestRender_SSRCircuitBreakerFailedProbe(t *testing.T) {
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			return inertia.RenderedPage{}, errors.New("broken bundle")
+		},
+	}
+	i := newSSRTestInertia(t, engine,
+		inertia.WithSSRFallback(true),
+		inertia.WithSSRCircuitBreaker(1, 20*time.Millisecond),
+	)
+
+	render := func() {
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		require.NoError(t, i.Render(w, req, "TestComponent", nil))
+	}
+
+	render()
+	opened := i.SSRHealth().OpenedAt
+	assert.Equal(t, inertia.SSRCircuitOpen, i.SSRHealth().State)
+
+	time.Sleep(30 * time.Millisecond)
+	render()
+
+	health := i.SSRHealth()
+	assert.Equal(t, inertia.SSRCircuitOpen, health.State)
+	assert.True(t, health.OpenedAt.After(opened))
+}
+
+func TestRender_SSRCircuitBreakerPanickingProbe(t *testing.T) {
+	var calls atomic.Int32
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			switch calls.Add(1) {
+			case 1:
+				return inertia.RenderedPage{}, errors.New("broken bundle")
+			case 2:
+				panic("engine crashed")
+			default:
+				return inertia.RenderedPage{Body: "<div>ssr</div>"}, nil
+			}
+		},
+	}
+	i := newSSRTestInertia(t, engine,
+		inertia.WithSSRFallback(true),
+		inertia.WithSSRCircuitBreaker(1, 20*time.Millisecond),
+	)
+
+	render := func() {
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		require.NoError(t, i.Render(w, req, "TestComponent", nil))
+	}
+
+	render()
+	assert.Equal(t, inertia.SSRCircuitOpen, i.SSRHealth().State)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.PanicsWithValue(t, "engine crashed", render)
+
+	health := i.SSRHealth()
+	assert.Equal(t, inertia.SSRCircuitOpen, health.State, "the panicking probe reopens the circuit")
+	assert.Contains(t, health.LastError, "engine crashed")
+
+	time.Sleep(30 * time.Millisecond)
+	render()
+	assert.Equal(t, inertia.SSRCircuitClosed, i.SSRHealth().State)
+}
+
+func TestRender_SSRCircuitBreakerWithoutFallback(t *testing.T) {
+	errBroken := errors.New("broken bundle")
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			return inertia.RenderedPage{}, errBroken
+		},
+	}
+	i := newSSRTestInertia(t, engine, inertia.WithSSRCircuitBreaker(1, time.Minute))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	assert.ErrorIs(t, i.Render(w, req, "TestComponent", nil), errBroken)
+
+	// An open circuit always serves client-rendered pages.
+	w = httptest.NewRecorder()
+	require.NoError(t, i.Render(w, req, "TestComponent", nil))
+	assert.Contains(t, w.Body.String(), `data-page="`)
+}
+
+// countingSSREngine renders the component name and a render counter, so
+// tests can tell fresh renders from cached ones.
+func countingSSREngine() (*fakeSSREngine, *atomic.Int32) {
+	var renders atomic.Int32
+	return &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			n := renders.Add(1)
+			return inertia.RenderedPage{Body: fmt.Sprintf("%s#%d", page.Component, n)}, nil
+		},
+	}, &renders
+}
+
+func renderSSRBody(t *testing.T, i *inertia.Inertia, url, component string, props inertia.Props, options ...inertia.RenderOption) string {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+
+	err := i.Render(w, req, component, props, options...)
+	require.NoError(t, err)
+
+	body := strings.TrimPrefix(w.Body.String(), "<html><head></head><body>")
+	return strings.TrimSuffix(body, "</body></html>")
+}
+
+func TestRender_SSRCache(t *testing.T) {
+	engine, renders := countingSSREngine()
+	i := newSSRTestInertia(t, engine, inertia.WithSSRCache(100, 0))
+
+	props := inertia.Props{"title": inertia.Value("Hello")}
+
+	assert.Equal(t, "Home#1", renderSSRBody(t, i, "/", "Home", props))
+	assert.Equal(t, "Home#1", renderSSRBody(t, i, "/", "Home", props), "same page is served from the cache")
+
+	otherProps := inertia.Props{"title": inertia.Value("Bye")}
+	assert.Equal(t, "Home#2", renderSSRBody(t, i, "/", "Home", otherProps), "different props render again")
+	assert.Equal(t, "Home#3", renderSSRBody(t, i, "/?page=2", "Home", props, inertia.WithoutSSRCache()))
+	assert.Equal(t, "Home#4", renderSSRBody(t, i, "/", "Home", props, inertia.WithoutSSRCache()))
+
+	assert.Equal(t, "About#5", renderSSRBody(t, i, "/about", "About", nil))
+
+	i.InvalidateSSRCache("Home")
+	assert.Equal(t, "Home#6", renderSSRBody(t, i, "/", "Home", props))
+	assert.Equal(t, "About#5", renderSSRBody(t, i, "/about", "About", nil), "other components stay cached")
+
+	i.PurgeSSRCache()
+	assert.Equal(t, "About#7", renderSSRBody(t, i, "/about", "About", nil))
+
+	assert.Equal(t, int32(7), renders.Load())
+}
+
+func TestRender_SSRCacheKey(t *testing.T) {
+	engine, _ := countingSSREngine()
+	i := newSSRTestInertia(t, engine, inertia.WithSSRCache(100, 0))
+
+	assert.Equal(t, "Home#1", renderSSRBody(t, i, "/", "Home", inertia.Props{"n": inertia.Value(1)}, inertia.WithSSRCacheKey("home")))
+	assert.Equal(t, "Home#1", renderSSRBody(t, i, "/", "Home", inertia.Props{"n": inertia.Value(2)}, inertia.WithSSRCacheKey("home")))
+
+	i.InvalidateSSRCacheKey("home")
+	assert.Equal(t, "Home#2", renderSSRBody(t, i, "/", "Home", nil, inertia.WithSSRCacheKey("home")))
+}
+
+func TestRender_SSRCacheEviction(t *testing.T) {
+	engine, _ := countingSSREngine()
+	i := newSSRTestInertia(t, engine, inertia.WithSSRCache(2, 0))
+
+	assert.Equal(t, "A#1", renderSSRBody(t, i, "/", "A", nil))
+	assert.Equal(t, "B#2", renderSSRBody(t, i, "/", "B", nil))
+	assert.Equal(t, "A#1", renderSSRBody(t, i, "/", "A", nil), "A becomes most recently used")
+	assert.Equal(t, "C#3", renderSSRBody(t, i, "/", "C", nil), "C evicts B")
+
+	assert.Equal(t, "A#1", renderSSRBody(t, i, "/", "A", nil))
+	assert.Equal(t, "B#4", renderSSRBody(t, i, "/", "B", nil))
+}
+
+func TestRender_SSRCacheTTL(t *testing.T) {
+	engine, _ := countingSSREngine()
+	i := newSSRTestInertia(t, engine, inertia.WithSSRCache(10, 20*time.Millisecond))
+
+	assert.Equal(t, "Home#1", renderSSRBody(t, i, "/", "Home", nil))
+	assert.Equal(t, "Home#1", renderSSRBody(t, i, "/", "Home", nil))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, "Home#2", renderSSRBody(t, i, "/", "Home", nil))
+}
+
+func TestRender_SSRCacheSkipsFailures(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			if fail.Load() {
+				return inertia.RenderedPage{}, errors.New("broken bundle")
+			}
+			return inertia.RenderedPage{Body: "rendered"}, nil
+		},
+	}
+	i := newSSRTestInertia(t, engine, inertia.WithSSRCache(10, 0), inertia.WithSSRFallback(true))
+
+	assert.Contains(t, renderSSRBody(t, i, "/", "Home", nil), `data-page=`)
+
+	fail.Store(false)
+	assert.Equal(t, "rendered", renderSSRBody(t, i, "/", "Home", nil))
+}
+
+func TestWithSSRCache_InvalidSize(t *testing.T) {
+	bundler, err := vite.New(nil)
+	require.NoError(t, err)
+
+	_, err = inertia.New(bundler, inertia.WithSSRCache(0, time.Minute))
+	assert.Error(t, err)
+}
+
+func TestRender_SSRStreaming(t *testing.T) {
+	release := make(chan struct{})
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			<-release
+			return inertia.RenderedPage{Head: []string{"<title>Home</title>"}, Body: "<div>rendered</div>"}, nil
+		},
+	}
+
+	rootFS := fstest.MapFS{
+		"index.html": &fstest.MapFile{
+			Data: []byte(`<html><head><script src="/app.js"></script>{{ .InertiaHead }}</head><body>{{ .InertiaBody }}</body></html>`),
+		},
+	}
+	i := newSSRTestInertia(t, engine, inertia.WithStreaming(true), inertia.WithRootHtmlPathFS(rootFS, "index.html"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, i.Render(w, r, "Home", nil))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	// The asset tags arrive while the render is still blocked.
+	prefix := `<html><head><script src="/app.js"></script>`
+	buf := make([]byte, len(prefix))
+	_, err = io.ReadFull(resp.Body, buf)
+	require.NoError(t, err)
+	assert.Equal(t, prefix, string(buf))
+
+	close(release)
+
+	rest, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `<title>Home</title></head><body><div>rendered</div></body></html>`, string(rest))
+}
+
+func TestRender_SSRStreamingFallsBack(t *testing.T) {
+	var handled atomic.Bool
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			return inertia.RenderedPage{}, errors.New("broken bundle")
+		},
+	}
+	i := newSSRTestInertia(t, engine,
+		inertia.WithStreaming(true),
+		inertia.WithSSRErrorHandler(func(r *http.Request, page *inertia.PageObject, err error) {
+			handled.Store(true)
+		}),
+	)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	err := i.Render(w, req, "Home", nil)
+	require.NoError(t, err, "streamed renders fall back even without WithSSRFallback")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<body><div id="app" data-page="`)
+	assert.True(t, handled.Load())
+}
+
+func TestRender_SSRStreamingWithoutPlaceholders(t *testing.T) {
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			return inertia.RenderedPage{Head: []string{"<title>Home</title>"}, Body: "<div>rendered</div>"}, nil
+		},
+	}
+
+	rootFS := fstest.MapFS{
+		"index.html": &fstest.MapFile{Data: []byte(`<html><body>{{ .InertiaBody }}</body></html>`)},
+	}
+	i := newSSRTestInertia(t, engine, inertia.WithStreaming(true), inertia.WithRootHtmlPathFS(rootFS, "index.html"))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	err := i.Render(w, req, "Home", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `<html><body><div>rendered</div></body></html>`, w.Body.String())
+}
+
+// flushErrorWriter is a ResponseWriter whose Flush fails.
+type flushErrorWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w flushErrorWriter) FlushError() error {
+	return errors.New("connection reset")
+}
+
+func TestRender_SSRStreamingWaitsForRenderOnError(t *testing.T) {
+	var finished atomic.Bool
+	engine := &fakeSSREngine{
+		render: func(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
+			time.Sleep(50 * time.Millisecond)
+			finished.Store(true)
+			return inertia.RenderedPage{Body: "<div>rendered</div>"}, nil
+		},
+	}
+	i := newSSRTestInertia(t, engine, inertia.WithStreaming(true))
+
+	w := flushErrorWriter{httptest.NewRecorder()}
+	err := i.Render(w, httptest.NewRequest("GET", "/", nil), "Home", inertia.Props{"a": inertia.Value(1)})
+	assert.ErrorContains(t, err, "connection reset")
+	assert.True(t, finished.Load(), "the render still using the props finished before Render returned")
+}
@@ -0,0 +1,47 @@
+// Package redisstore is an inertia.SessionStore backed by Redis, or a
+// compatible server such as Valkey or DragonflyDB.
+//
+//	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//
+//	i, err := inertia.New(bundler,
+//	    inertia.WithSession(inertia.NewStoreSession("sid", redisstore.New(client))),
+//	)
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store keeps session values in Redis, which expires them by itself.
+type Store struct {
+	client redis.Cmdable
+}
+
+// New returns a Store using client, which can be a *redis.Client,
+// *redis.ClusterClient or any other redis.Cmdable.
+func New(client redis.Cmdable) *Store {
+	return &Store{client: client}
+}
+
+// Set stores value under key with SET and an expiry of ttl, or none if ttl
+// is 0 or less.
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// go-redis reads a negative expiry as KEEPTTL, which would keep the
+	// expiry of the value being replaced.
+	ttl = max(ttl, 0)
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// GetDel reads and deletes the value under key with GETDEL, which needs
+// Redis 6.2 or later.
+func (s *Store) GetDel(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.GetDel(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return value, err
+}
@@ -0,0 +1,44 @@
+package redisstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joetifa2003/inertigo/sessiontest"
+	redisstore "github.com/joetifa2003/inertigo/stores/redis"
+)
+
+func newStore(t *testing.T) (*redisstore.Store, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return redisstore.New(client), mr
+}
+
+func TestStore(t *testing.T) {
+	store, _ := newStore(t)
+	sessiontest.RunStoreTests(t, store)
+}
+
+func TestStore_TTL(t *testing.T) {
+	store, mr := newStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "flash:1", []byte("value"), time.Minute))
+	assert.Equal(t, time.Minute, mr.TTL("flash:1"))
+
+	mr.FastForward(2 * time.Minute)
+
+	value, err := store.GetDel(ctx, "flash:1")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
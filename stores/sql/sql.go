@@ -0,0 +1,265 @@
+// Package sqlstore is an inertia.SessionStore backed by a database/sql
+// table, for apps that already have a database and no Redis.
+//
+//	store := sqlstore.New(db, sqlstore.Postgres, sqlstore.WithCleanupInterval(10*time.Minute))
+//	defer store.Close()
+//
+//	if err := store.Migrate(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	i, err := inertia.New(bundler,
+//	    inertia.WithSession(inertia.NewStoreSession("sid", store)),
+//	)
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Dialect holds the SQL statements for one database. In each statement,
+// %[1]s is the table name. The table has the columns id, data and
+// expires_at, the expiry in Unix milliseconds.
+type Dialect struct {
+	// Migrate creates the table and its indexes if they don't exist.
+	Migrate []string
+
+	// Set inserts or replaces a row. Its arguments are id, data and
+	// expires_at.
+	Set string
+
+	// Take deletes a row and returns its data and expires_at, atomically,
+	// e.g. with DELETE ... RETURNING. Its argument is id. If the database
+	// cannot do this in one statement, leave Take empty and set Select and
+	// Delete instead.
+	Take string
+
+	// Select locks a row and returns its data and expires_at, e.g. with
+	// SELECT ... FOR UPDATE. It is followed by Delete in the same
+	// transaction. Both take id as their argument.
+	Select string
+	Delete string
+
+	// DeleteExpired deletes the rows with an expires_at at or before its
+	// argument.
+	DeleteExpired string
+}
+
+// SQLite is the Dialect for SQLite 3.35 or later.
+var SQLite = Dialect{
+	Migrate: []string{
+		`CREATE TABLE IF NOT EXISTS %[1]s (id TEXT PRIMARY KEY, data BLOB NOT NULL, expires_at INTEGER NOT NULL)`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_expires_at ON %[1]s (expires_at)`,
+	},
+	Set:           `INSERT INTO %[1]s (id, data, expires_at) VALUES (?, ?, ?) ON CONFLICT (id) DO UPDATE SET data = excluded.data, expires_at = excluded.expires_at`,
+	Take:          `DELETE FROM %[1]s WHERE id = ? RETURNING data, expires_at`,
+	DeleteExpired: `DELETE FROM %[1]s WHERE expires_at <= ?`,
+}
+
+// Postgres is the Dialect for PostgreSQL.
+var Postgres = Dialect{
+	Migrate: []string{
+		`CREATE TABLE IF NOT EXISTS %[1]s (id TEXT PRIMARY KEY, data BYTEA NOT NULL, expires_at BIGINT NOT NULL)`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_expires_at ON %[1]s (expires_at)`,
+	},
+	Set:           `INSERT INTO %[1]s (id, data, expires_at) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data, expires_at = EXCLUDED.expires_at`,
+	Take:          `DELETE FROM %[1]s WHERE id = $1 RETURNING data, expires_at`,
+	DeleteExpired: `DELETE FROM %[1]s WHERE expires_at <= $1`,
+}
+
+// MySQL is the Dialect for MySQL and MariaDB.
+var MySQL = Dialect{
+	Migrate: []string{
+		`CREATE TABLE IF NOT EXISTS %[1]s (id VARCHAR(255) PRIMARY KEY, data MEDIUMBLOB NOT NULL, expires_at BIGINT NOT NULL, INDEX %[1]s_expires_at (expires_at))`,
+	},
+	Set:           `INSERT INTO %[1]s (id, data, expires_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE data = VALUES(data), expires_at = VALUES(expires_at)`,
+	Select:        `SELECT data, expires_at FROM %[1]s WHERE id = ? FOR UPDATE`,
+	Delete:        `DELETE FROM %[1]s WHERE id = ?`,
+	DeleteExpired: `DELETE FROM %[1]s WHERE expires_at <= ?`,
+}
+
+// Store keeps session values in a SQL table. Expired rows are never
+// returned; they are removed by DeleteExpired, which can be run
+// periodically with WithCleanupInterval.
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	table   string
+
+	cleanupInterval time.Duration
+	onCleanupError  func(err error)
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Option configures a Store.
+type Option func(s *Store)
+
+// WithTable sets the table name, "inertia_sessions" by default. It is
+// written into the statements as is.
+func WithTable(table string) Option {
+	return func(s *Store) {
+		s.table = table
+	}
+}
+
+// WithCleanupInterval runs DeleteExpired every interval in the background,
+// until Close is called.
+func WithCleanupInterval(interval time.Duration) Option {
+	return func(s *Store) {
+		s.cleanupInterval = interval
+	}
+}
+
+// WithCleanupErrorHandler sets a function called with the errors of the
+// background cleanup, which are ignored by default.
+func WithCleanupErrorHandler(fn func(err error)) Option {
+	return func(s *Store) {
+		s.onCleanupError = fn
+	}
+}
+
+// New returns a Store using db with the statements of dialect. Call Migrate
+// to create the table.
+func New(db *sql.DB, dialect Dialect, options ...Option) *Store {
+	s := &Store{
+		db:      db,
+		dialect: dialect,
+		table:   "inertia_sessions",
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	if s.cleanupInterval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.cleanup()
+	}
+
+	return s
+}
+
+func (s *Store) query(stmt string) string {
+	return fmt.Sprintf(stmt, s.table)
+}
+
+// Migrate creates the table if it doesn't exist.
+func (s *Store) Migrate(ctx context.Context) error {
+	for _, stmt := range s.dialect.Migrate {
+		if _, err := s.db.ExecContext(ctx, s.query(stmt)); err != nil {
+			return fmt.Errorf("sqlstore: migrate: %w", err)
+		}
+	}
+	return nil
+}
+
+// Set stores value under key until ttl has passed, or until it is read if
+// ttl is 0 or less.
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	var expiresAt int64 = math.MaxInt64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UnixMilli()
+	}
+	_, err := s.db.ExecContext(ctx, s.query(s.dialect.Set), key, value, expiresAt)
+	return err
+}
+
+// GetDel returns the value under key and deletes it. It returns nil if there
+// is no value or it has expired.
+func (s *Store) GetDel(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	var expiresAt int64
+	var err error
+
+	if s.dialect.Take != "" {
+		err = s.db.QueryRowContext(ctx, s.query(s.dialect.Take), key).Scan(&value, &expiresAt)
+	} else {
+		value, expiresAt, err = s.selectDelete(ctx, key)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().UnixMilli() >= expiresAt {
+		return nil, nil
+	}
+	return value, nil
+}
+
+// selectDelete takes a row with the dialect's Select and Delete statements.
+func (s *Store) selectDelete(ctx context.Context, key string) ([]byte, int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var value []byte
+	var expiresAt int64
+	err = tx.QueryRowContext(ctx, s.query(s.dialect.Select), key).Scan(&value, &expiresAt)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, s.query(s.dialect.Delete), key); err != nil {
+		return nil, 0, err
+	}
+
+	return value, expiresAt, tx.Commit()
+}
+
+// DeleteExpired deletes the expired rows and returns how many there were.
+func (s *Store) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.query(s.dialect.DeleteExpired), time.Now().UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Close stops the background cleanup, if any. It does not close the
+// database.
+func (s *Store) Close() error {
+	if s.stop == nil {
+		return nil
+	}
+
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+	return nil
+}
+
+func (s *Store) cleanup() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			_, err := s.DeleteExpired(context.Background())
+			if err != nil && s.onCleanupError != nil {
+				s.onCleanupError(err)
+			}
+		}
+	}
+}
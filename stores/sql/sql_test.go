@@ -0,0 +1,99 @@
+package sqlstore_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/joetifa2003/inertigo/sessiontest"
+	sqlstore "github.com/joetifa2003/inertigo/stores/sql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "sessions.db")+"?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func newStore(t *testing.T, db *sql.DB, options ...sqlstore.Option) *sqlstore.Store {
+	t.Helper()
+
+	store := sqlstore.New(db, sqlstore.SQLite, options...)
+	t.Cleanup(func() { store.Close() })
+	require.NoError(t, store.Migrate(context.Background()))
+
+	return store
+}
+
+func TestStore(t *testing.T) {
+	sessiontest.RunStoreTests(t, newStore(t, openDB(t)))
+}
+
+func TestStore_SelectDelete(t *testing.T) {
+	// SQLite with the transaction fallback used by dialects without
+	// DELETE ... RETURNING. SQLite has no FOR UPDATE, but serializes the
+	// transactions on its single connection.
+	dialect := sqlstore.SQLite
+	dialect.Take = ""
+	dialect.Select = `SELECT data, expires_at FROM %[1]s WHERE id = ?`
+	dialect.Delete = `DELETE FROM %[1]s WHERE id = ?`
+
+	db := openDB(t)
+	db.SetMaxOpenConns(1)
+
+	store := sqlstore.New(db, dialect)
+	require.NoError(t, store.Migrate(context.Background()))
+
+	sessiontest.RunStoreTests(t, store)
+}
+
+func TestStore_Expiry(t *testing.T) {
+	db := openDB(t)
+	store := newStore(t, db, sqlstore.WithTable("flash"))
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "expired", []byte("value"), time.Millisecond))
+	require.NoError(t, store.Set(ctx, "live", []byte("value"), time.Minute))
+	time.Sleep(5 * time.Millisecond)
+
+	value, err := store.GetDel(ctx, "expired")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	require.NoError(t, store.Set(ctx, "expired", []byte("value"), time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	n, err := store.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+
+	var rows int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM flash`).Scan(&rows))
+	assert.Equal(t, 1, rows)
+}
+
+func TestStore_CleanupInterval(t *testing.T) {
+	db := openDB(t)
+	store := newStore(t, db, sqlstore.WithCleanupInterval(10*time.Millisecond))
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "expired", []byte("value"), time.Millisecond))
+
+	assert.Eventually(t, func() bool {
+		var rows int
+		require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM inertia_sessions`).Scan(&rows))
+		return rows == 0
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, store.Close())
+	require.NoError(t, store.Close())
+}
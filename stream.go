@@ -0,0 +1,113 @@
+package inertia
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Placeholders executed into the root template to find where the SSR head
+// and body go.
+const (
+	streamHeadMarker = "<!--inertia:head-->"
+	streamBodyMarker = "<!--inertia:body-->"
+)
+
+// streamHTML writes the root template up to {{ .InertiaHead }} while the
+// page is rendered server-side, then the rest once the render is done.
+func (i *Inertia) streamHTML(w http.ResponseWriter, r *http.Request, page *PageObject, config *renderConfig) error {
+	type result struct {
+		head []string
+		body string
+		err  error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				done <- result{err: fmt.Errorf("ssr render panicked: %v", v)}
+			}
+		}()
+
+		head, body, err := i.renderPageBody(r, page, config, true)
+		done <- result{head: head, body: body, err: err}
+	}()
+
+	// The render reads page.Props, which go back to a pool when Render
+	// returns, so wait for it on every path, including early returns.
+	wait := sync.OnceValue(func() result { return <-done })
+	defer wait()
+
+	var shell bytes.Buffer
+	err := i.rootTemplate.Execute(&shell, RootHtmlView{
+		InertiaHead: streamHeadMarker,
+		InertiaBody: streamBodyMarker,
+	})
+	if err != nil {
+		return err
+	}
+
+	before, between, after, ok := splitShell(shell.Bytes())
+	if !ok {
+		// The placeholders are missing, repeated or out of order, so there
+		// is no safe point to split at; render in one go instead.
+		res := wait()
+		if res.err != nil {
+			return res.err
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		config.writeStatus(w)
+		return i.rootTemplate.Execute(w, RootHtmlView{
+			InertiaHead: template.HTML(strings.Join(res.head, "\n")),
+			InertiaBody: template.HTML(res.body),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	config.writeStatus(w)
+	if _, err := w.Write(before); err != nil {
+		return err
+	}
+
+	err = http.NewResponseController(w).Flush()
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	res := wait()
+	if res.err != nil {
+		return res.err
+	}
+
+	for _, part := range [][]byte{
+		[]byte(strings.Join(res.head, "\n")),
+		between,
+		[]byte(res.body),
+		after,
+	} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitShell splits the executed root template around the head and body
+// placeholders. It reports false unless each appears exactly once, head
+// first.
+func splitShell(shell []byte) (before, between, after []byte, ok bool) {
+	if bytes.Count(shell, []byte(streamHeadMarker)) != 1 || bytes.Count(shell, []byte(streamBodyMarker)) != 1 {
+		return nil, nil, nil, false
+	}
+
+	before, rest, _ := bytes.Cut(shell, []byte(streamHeadMarker))
+	between, after, ok = bytes.Cut(rest, []byte(streamBodyMarker))
+	return before, between, after, ok
+}
@@ -0,0 +1,402 @@
+// Package tsgen generates TypeScript declarations for the props of typed
+// pages, so the frontend's types follow the Go structs passed to
+// inertia.RenderTyped and inertia.ShareTyped.
+//
+// Register every page in a small program and run it with go generate:
+//
+//	//go:generate go run ./cmd/gentypes
+//
+//	func main() {
+//	    g := tsgen.New()
+//	    g.Shared(app.SharedProps{})
+//	    g.Page("users/Index", app.UsersPage{})
+//	    g.Page("users/Show", app.UserPage{})
+//
+//	    if err := g.WriteFile("resources/js/types/pages.d.ts"); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+//
+// The inertigo CLI's gen-types command writes and runs such a program for
+// a package exporting a func(*tsgen.Generator) that registers the pages.
+package tsgen
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/internal/propstruct"
+)
+
+// Generator collects typed pages and writes their TypeScript declarations.
+type Generator struct {
+	shared []reflect.Type
+	pages  map[string]reflect.Type
+}
+
+// New returns an empty Generator.
+func New() *Generator {
+	return &Generator{pages: map[string]reflect.Type{}}
+}
+
+// Shared adds the fields of props, a struct passed to inertia.ShareTyped, to
+// the props every page has.
+func (g *Generator) Shared(props any) *Generator {
+	g.shared = append(g.shared, reflect.TypeOf(props))
+	return g
+}
+
+// Page registers the props struct rendered for component.
+func (g *Generator) Page(component string, props any) *Generator {
+	g.pages[component] = reflect.TypeOf(props)
+	return g
+}
+
+// Generate returns the contents of a .d.ts file declaring:
+//
+//   - an interface for each named struct type the props use,
+//   - SharedProps, holding the shared props and the errors bag,
+//   - Pages, mapping each component name to its props,
+//   - PageProps<C>, the props of component C.
+//
+// Deferred and optional props are optional fields, since they are missing
+// until the client asks for them.
+func (g *Generator) Generate() ([]byte, error) {
+	e := &emitter{
+		names: map[reflect.Type]string{},
+		taken: map[string]reflect.Type{},
+		decls: map[string]string{},
+	}
+
+	var shared strings.Builder
+	shared.WriteString("export interface SharedProps {\n  errors: Record<string, string>;\n")
+	for _, t := range g.shared {
+		if err := e.props(&shared, t, "  "); err != nil {
+			return nil, err
+		}
+	}
+	shared.WriteString("}\n")
+
+	var pages strings.Builder
+	pages.WriteString("export interface Pages {\n")
+	for _, component := range slices.Sorted(maps.Keys(g.pages)) {
+		fmt.Fprintf(&pages, "  %s: SharedProps & {\n", strconv.Quote(component))
+		if err := e.props(&pages, g.pages[component], "    "); err != nil {
+			return nil, fmt.Errorf("tsgen: page %q: %w", component, err)
+		}
+		pages.WriteString("  };\n")
+	}
+	pages.WriteString("}\n")
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by inertigo tsgen. DO NOT EDIT.\n\n")
+	for _, name := range slices.Sorted(maps.Keys(e.decls)) {
+		buf.WriteString(e.decls[name])
+		buf.WriteString("\n")
+	}
+	buf.WriteString(shared.String())
+	buf.WriteString("\n")
+	buf.WriteString(pages.String())
+	buf.WriteString("\nexport type PageProps<C extends keyof Pages> = Pages[C];\n")
+
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes the generated declarations to path.
+func (g *Generator) WriteFile(path string) error {
+	data, err := g.Generate()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+var (
+	propType          = reflect.TypeFor[inertia.Prop]()
+	propsType         = reflect.TypeFor[inertia.Props]()
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// emitter converts Go types to TypeScript, collecting a declaration for each
+// named struct type it meets.
+type emitter struct {
+	names map[reflect.Type]string
+	taken map[string]reflect.Type
+	decls map[string]string
+}
+
+// props writes a field for each prop of the props struct t.
+func (e *emitter) props(b *strings.Builder, t reflect.Type, indent string) error {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("tsgen: props must be a struct, got %v", t)
+	}
+
+	fields, err := propstruct.Fields(t, propType)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		var ts string
+		switch {
+		case field.Type == propsType:
+			ts = "Record<string, unknown>"
+		case field.Prop:
+			ts = "unknown"
+		case field.Resolver:
+			ts, err = e.typeOf(field.Type.Out(0))
+		default:
+			ts, err = e.typeOf(field.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("prop %q: %w", field.Name, err)
+		}
+
+		optional := field.Kind == "optional" || field.Kind == "deferred"
+		writeField(b, indent, field.Name, optional, ts, ";\n")
+	}
+
+	return nil
+}
+
+func writeField(b *strings.Builder, indent, name string, optional bool, ts, sep string) {
+	b.WriteString(indent)
+	b.WriteString(propertyName(name))
+	if optional {
+		b.WriteString("?")
+	}
+	b.WriteString(": ")
+	b.WriteString(ts)
+	b.WriteString(sep)
+}
+
+// typeOf returns the TypeScript type of t's JSON encoding.
+func (e *emitter) typeOf(t reflect.Type) (string, error) {
+	switch {
+	case t == timeType:
+		return "string", nil
+	case t == rawMessageType:
+		return "unknown", nil
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return "unknown", nil
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return "string", nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number", nil
+	case reflect.String:
+		return "string", nil
+	case reflect.Interface:
+		return "unknown", nil
+	case reflect.Pointer:
+		elem, err := e.typeOf(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return elem + " | null", nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return "string", nil // base64
+		}
+		elem, err := e.typeOf(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(elem, " | ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]", nil
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !t.Key().Implements(textMarshalerType) {
+				return "", fmt.Errorf("unsupported map key type %s", t.Key())
+			}
+		}
+		elem, err := e.typeOf(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "Record<string, " + elem + ">", nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			var b strings.Builder
+			b.WriteString("{ ")
+			if err := e.structFields(&b, t, "", "; "); err != nil {
+				return "", err
+			}
+			b.WriteString("}")
+			return b.String(), nil
+		}
+		return e.named(t)
+	default:
+		return "", fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// named returns the interface name for struct type t, declaring it the
+// first time t is seen.
+func (e *emitter) named(t reflect.Type) (string, error) {
+	if name, ok := e.names[t]; ok {
+		return name, nil
+	}
+
+	name := e.uniqueName(t)
+	e.names[t] = name
+	e.taken[name] = t
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "export interface %s {\n", name)
+	if err := e.structFields(&b, t, "  ", ";\n"); err != nil {
+		return "", err
+	}
+	b.WriteString("}\n")
+	e.decls[name] = b.String()
+
+	return name, nil
+}
+
+// uniqueName picks an interface name for t, qualifying it with its package
+// name if another type already has the short one.
+func (e *emitter) uniqueName(t reflect.Type) string {
+	base := identifier(t.Name())
+	if _, ok := e.taken[base]; !ok {
+		return base
+	}
+
+	pkg := t.PkgPath()
+	pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+	name := identifier(pkg + "_" + t.Name())
+	for i := 2; ; i++ {
+		if _, ok := e.taken[name]; !ok {
+			return name
+		}
+		name = identifier(pkg+"_"+t.Name()) + strconv.Itoa(i)
+	}
+}
+
+// structFields writes t's fields as encoding/json would encode them.
+func (e *emitter) structFields(b *strings.Builder, t reflect.Type, indent, sep string) error {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := sf.Type
+		if sf.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := e.structFields(b, ft, indent, sep); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		ts, err := e.typeOf(sf.Type)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t, sf.Name, err)
+		}
+		if hasOption(opts, "string") {
+			ts = "string"
+		}
+
+		optional := hasOption(opts, "omitempty") || hasOption(opts, "omitzero")
+		writeField(b, indent, name, optional, ts, sep)
+	}
+
+	return nil
+}
+
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// identifier turns a Go type name, which may include type arguments such as
+// Page[[]example.com/app.User], into a TypeScript identifier like PageUser.
+func identifier(name string) string {
+	var b, word strings.Builder
+	upper := false
+	flush := func() {
+		b.WriteString(word.String())
+		word.Reset()
+	}
+
+	for _, r := range name {
+		switch {
+		case r == '.' || r == '/':
+			// Drop package paths, keeping only the type name.
+			word.Reset()
+			upper = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			word.WriteRune(r)
+		default:
+			flush()
+			upper = true
+		}
+	}
+	flush()
+
+	return b.String()
+}
+
+// propertyName quotes name unless it is a valid identifier.
+func propertyName(name string) string {
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || r == '$' || (i > 0 && unicode.IsDigit(r))) {
+			return strconv.Quote(name)
+		}
+	}
+	if name == "" {
+		return `""`
+	}
+	return name
+}
@@ -0,0 +1,132 @@
+package tsgen_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/tsgen"
+)
+
+type User struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	Manager   *User     `json:"manager"`
+	CreatedAt time.Time `json:"createdAt"`
+	Password  string    `json:"-"`
+	Timestamps
+}
+
+type Timestamps struct {
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+type SharedProps struct {
+	AppName string                  `inertia:"appName,always"`
+	Auth    inertia.Resolver[*User] `inertia:"auth"`
+}
+
+type UsersPage struct {
+	Users   inertia.Resolver[Page[User]]      `inertia:"users"`
+	Filters map[string]string                 `inertia:"filters"`
+	Stats   inertia.Resolver[struct{ N int }] `inertia:"stats,deferred"`
+	Roles   inertia.Resolver[[]string]        `inertia:"roles,optional"`
+	Feed    []string                          `inertia:"feed,merge"`
+	Custom  inertia.Prop                      `inertia:"custom"`
+	Nested  inertia.Props                     `inertia:"nested"`
+	Tags    []*string                         `inertia:"tags"`
+	Raw     []byte                            `inertia:"raw-data"`
+}
+
+type ShowPage struct {
+	User User `inertia:"user"`
+}
+
+func TestGenerate(t *testing.T) {
+	g := tsgen.New()
+	g.Shared(SharedProps{})
+	g.Page("users/Index", UsersPage{})
+	g.Page("users/Show", &ShowPage{})
+
+	out, err := g.Generate()
+	require.NoError(t, err)
+
+	assert.Equal(t, `// Code generated by inertigo tsgen. DO NOT EDIT.
+
+export interface PageUser {
+  items: User[];
+  total: number;
+}
+
+export interface User {
+  id: number;
+  name: string;
+  email?: string;
+  manager: User | null;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface SharedProps {
+  errors: Record<string, string>;
+  appName: string;
+  auth: User | null;
+}
+
+export interface Pages {
+  "users/Index": SharedProps & {
+    users: PageUser;
+    filters: Record<string, string>;
+    stats?: { N: number; };
+    roles?: string[];
+    feed: string[];
+    custom: unknown;
+    nested: Record<string, unknown>;
+    tags: (string | null)[];
+    "raw-data": string;
+  };
+  "users/Show": SharedProps & {
+    user: User;
+  };
+}
+
+export type PageProps<C extends keyof Pages> = Pages[C];
+`, string(out))
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	_, err := tsgen.New().Page("bad", map[string]any{}).Generate()
+	assert.ErrorContains(t, err, "must be a struct")
+
+	_, err = tsgen.New().Page("bad", struct {
+		C chan int `inertia:"c"`
+	}{}).Generate()
+	assert.ErrorContains(t, err, "unsupported type chan int")
+
+	_, err = tsgen.New().Page("bad", struct {
+		A string `inertia:"a,eager"`
+	}{}).Generate()
+	assert.ErrorContains(t, err, `unknown kind "eager"`)
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pages.d.ts")
+
+	err := tsgen.New().Page("Home", struct{}{}).WriteFile(path)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Home": SharedProps & {`)
+}
@@ -0,0 +1,168 @@
+package inertia
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/joetifa2003/inertigo/internal/propstruct"
+)
+
+// Resolver is a typed PropFunc, for struct fields holding lazily resolved
+// props.
+type Resolver[T any] func(ctx context.Context) (T, error)
+
+// RenderTyped renders component with props taken from the fields of a
+// struct, so each page's props are a single Go type:
+//
+//	type UsersPage struct {
+//	    Users   inertia.Resolver[[]User]  `inertia:"users"`
+//	    Stats   inertia.Resolver[Stats]   `inertia:"stats,deferred"`
+//	    Filters Filters                   `inertia:"filters"`
+//	    Roles   inertia.Resolver[[]Role]  `inertia:"roles,once"`
+//	}
+//
+//	inertia.RenderTyped(i, w, r, "users/index", UsersPage{...})
+//
+// See PropsOf for how fields are mapped to props.
+func RenderTyped[P any](i *Inertia, w http.ResponseWriter, r *http.Request, component string, props P, options ...RenderOption) error {
+	p, err := PropsOf(props)
+	if err != nil {
+		return err
+	}
+	return i.Render(w, r, component, p, options...)
+}
+
+// ShareTyped shares the props of a struct, as with ShareMultiple. See
+// PropsOf for how fields are mapped to props.
+func ShareTyped[P any](r *http.Request, props P) error {
+	p, err := PropsOf(props)
+	if err != nil {
+		return err
+	}
+	ShareMultiple(r, p)
+	return nil
+}
+
+// PropsOf converts a struct, or a pointer to one, to Props.
+//
+// Each exported field becomes a prop. The field's `inertia` tag has the form
+// "name,kind,options...". The name defaults to the field's json tag name,
+// then to the field name, and "-" skips the field. A field without an
+// `inertia` tag is also skipped if its json tag is "-". Embedded structs without
+// a tag are flattened into the parent.
+//
+// The kind picks the prop type:
+//
+//	value     Value (default for plain values)
+//	always    Always
+//	lazy      Lazy (default for resolvers)
+//	optional  Optional
+//	deferred  Deferred; "group=name" sets the group as with DeferredGroup
+//	once      Once
+//	merge     Merge; "deep" deep merges, "matchOn=field" matches items on field
+//
+// A resolver is a field of type func(context.Context) (T, error), such as
+// Resolver[T]. Fields tagged with any kind but value and always may hold a
+// resolver or a plain value. Fields whose type implements Prop are used as
+// they are and must not have a kind.
+func PropsOf[P any](props P) (Props, error) {
+	v := reflect.ValueOf(props)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, fmt.Errorf("inertia: PropsOf: nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("inertia: PropsOf: %s is not a struct", v.Type())
+	}
+
+	fields, err := propstruct.Fields(v.Type(), propType)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(Props, len(fields))
+	for _, field := range fields {
+		fv, ok := propstruct.FieldByIndex(v, field.Index)
+		if !ok {
+			continue
+		}
+
+		prop, err := typedProp(&field, fv)
+		if err != nil {
+			return nil, err
+		}
+		result[field.Name] = prop
+	}
+
+	return result, nil
+}
+
+var propType = reflect.TypeFor[Prop]()
+
+func typedProp(f *propstruct.Field, v reflect.Value) (Prop, error) {
+	if f.Prop {
+		if v.Kind() == reflect.Interface && v.IsNil() {
+			return nil, fmt.Errorf("inertia: field %s is a nil Prop", f.GoName)
+		}
+		return v.Interface().(Prop), nil
+	}
+
+	switch f.Kind {
+	case "value":
+		return Value(v.Interface()), nil
+	case "always":
+		return Always(v.Interface()), nil
+	}
+
+	resolver, err := typedResolver(f, v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch f.Kind {
+	case "optional":
+		return Optional(resolver), nil
+	case "deferred":
+		if f.Group != "" {
+			return DeferredGroup(f.Group, resolver), nil
+		}
+		return Deferred(resolver), nil
+	case "once":
+		return Once(resolver), nil
+	case "merge":
+		var opts []MergeOption
+		if f.Deep {
+			opts = append(opts, MergeDeepMerge())
+		}
+		if len(f.MatchOn) > 0 {
+			opts = append(opts, MergeMatchOn(f.MatchOn...))
+		}
+		return Merge(resolver, opts...), nil
+	default:
+		return Lazy(resolver), nil
+	}
+}
+
+func typedResolver(f *propstruct.Field, v reflect.Value) (PropFunc, error) {
+	if !f.Resolver {
+		value := v.Interface()
+		return func(ctx context.Context) (any, error) { return value, nil }, nil
+	}
+
+	if v.IsNil() {
+		return nil, fmt.Errorf("inertia: field %s has a nil resolver", f.GoName)
+	}
+
+	return func(ctx context.Context) (any, error) {
+		out := v.Call([]reflect.Value{reflect.ValueOf(&ctx).Elem()})
+		err, _ := out[1].Interface().(error)
+		if err != nil {
+			return nil, err
+		}
+		return out[0].Interface(), nil
+	}, nil
+}
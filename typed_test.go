@@ -0,0 +1,239 @@
+package inertia_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
+	"github.com/joetifa2003/inertigo/vite"
+)
+
+type typedUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type typedLayout struct {
+	AppName string `inertia:"appName,always"`
+}
+
+type typedUsersPage struct {
+	typedLayout
+
+	Users    inertia.Resolver[[]typedUser] `inertia:"users"`
+	Filters  map[string]string             `json:"filters"`
+	Stats    inertia.Resolver[int]         `inertia:"stats,deferred,group=slow"`
+	Roles    inertia.Resolver[[]string]    `inertia:"roles,optional"`
+	Feed     []string                      `inertia:"feed,merge,matchOn=id"`
+	Title    string
+	Custom   inertia.Prop `inertia:"custom"`
+	Internal string       `inertia:"-"`
+	private  string
+}
+
+func newTypedUsersPage() typedUsersPage {
+	return typedUsersPage{
+		typedLayout: typedLayout{AppName: "Inertigo"},
+		Users: func(ctx context.Context) ([]typedUser, error) {
+			return []typedUser{{ID: 1, Name: "Ada"}}, nil
+		},
+		Filters: map[string]string{"sort": "name"},
+		Stats: func(ctx context.Context) (int, error) {
+			return 42, nil
+		},
+		Roles: func(ctx context.Context) ([]string, error) {
+			return []string{"admin"}, nil
+		},
+		Feed:     []string{"a"},
+		Title:    "Users",
+		Custom:   inertia.Value("custom"),
+		Internal: "internal",
+		private:  "private",
+	}
+}
+
+func TestRenderTyped(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	t.Run("Full Load", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+
+		err := inertia.RenderTyped(i, w, req, "users/index", newTypedUsersPage())
+		require.NoError(t, err)
+
+		var resp inertia.PageObject
+		err = json.NewDecoder(w.Body).Decode(&resp)
+		require.NoError(t, err)
+
+		delete(resp.Props, "errors")
+		actual, err := json.Marshal(resp.Props)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"appName": "Inertigo",
+			"users": [{"id": 1, "name": "Ada"}],
+			"filters": {"sort": "name"},
+			"feed": ["a"],
+			"Title": "Users",
+			"custom": "custom"
+		}`, string(actual))
+		assert.Equal(t, map[string][]string{"slow": {"stats"}}, resp.DeferredProps)
+		assert.Equal(t, []string{"feed"}, resp.MergeProps)
+		assert.Equal(t, []string{"feed.id"}, resp.MatchPropsOn)
+	})
+
+	t.Run("Partial Reload", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		req.Header.Set(inertia.XInertiaPartialComponent, "users/index")
+		req.Header.Set(inertia.XInertiaPartialData, "stats,roles")
+		w := httptest.NewRecorder()
+
+		page := newTypedUsersPage()
+		err := inertia.RenderTyped(i, w, req, "users/index", &page)
+		require.NoError(t, err)
+
+		var resp inertia.PageObject
+		err = json.NewDecoder(w.Body).Decode(&resp)
+		require.NoError(t, err)
+
+		assert.Equal(t, float64(42), resp.Props["stats"])
+		assert.Equal(t, []any{"admin"}, resp.Props["roles"])
+		assert.Equal(t, "Inertigo", resp.Props["appName"])
+		assert.NotContains(t, resp.Props, "users")
+	})
+
+	t.Run("Resolver Error", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.Header.Set(inertia.XInertia, "true")
+		w := httptest.NewRecorder()
+
+		errBoom := errors.New("boom")
+		page := newTypedUsersPage()
+		page.Users = func(ctx context.Context) ([]typedUser, error) {
+			return nil, errBoom
+		}
+
+		err := inertia.RenderTyped(i, w, req, "users/index", page)
+		assert.ErrorIs(t, err, errBoom)
+	})
+}
+
+func TestPropsOf_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		props any
+		err   string
+	}{
+		{
+			name:  "not a struct",
+			props: map[string]string{},
+			err:   "is not a struct",
+		},
+		{
+			name: "unknown kind",
+			props: struct {
+				A string `inertia:"a,eager"`
+			}{},
+			err: `unknown kind "eager"`,
+		},
+		{
+			name: "unknown option",
+			props: struct {
+				A string `inertia:"a,lazy,group=x"`
+			}{},
+			err: `unknown option "group=x"`,
+		},
+		{
+			name: "resolver as value",
+			props: struct {
+				A inertia.Resolver[int] `inertia:"a,value"`
+			}{A: func(ctx context.Context) (int, error) { return 1, nil }},
+			err: `kind "value" cannot hold a resolver`,
+		},
+		{
+			name: "prop with kind",
+			props: struct {
+				A inertia.Prop `inertia:"a,deferred"`
+			}{A: inertia.Value(1)},
+			err: "cannot have kind",
+		},
+		{
+			name: "nil resolver",
+			props: struct {
+				A inertia.Resolver[int] `inertia:"a"`
+			}{},
+			err: "nil resolver",
+		},
+		{
+			name: "duplicate name",
+			props: struct {
+				A string `inertia:"a"`
+				B string `json:"a"`
+			}{},
+			err: `both named "a"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := inertia.PropsOf(tt.props)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestPropsOf_JSONSkip(t *testing.T) {
+	props, err := inertia.PropsOf(struct {
+		Name     string `json:"name"`
+		Password string `json:"-"`
+		Token    string `json:"-" inertia:"token"`
+	}{Name: "Ada", Password: "secret", Token: "t"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"name", "token"}, slices.Sorted(maps.Keys(props)))
+}
+
+func TestShareTyped(t *testing.T) {
+	bundler, err := vite.New(nil, vite.WithDevMode(true))
+	require.NoError(t, err)
+
+	i, err := inertia.New(bundler)
+	require.NoError(t, err)
+
+	handler := i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := inertia.ShareTyped(r, typedLayout{AppName: "Inertigo"})
+		require.NoError(t, err)
+
+		err = i.Render(w, r, "Home", inertia.Props{"title": inertia.Value("Home")})
+		require.NoError(t, err)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(inertia.XInertia, "true")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var resp inertia.PageObject
+	err = json.NewDecoder(w.Body).Decode(&resp)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Inertigo", resp.Props["appName"])
+	assert.Equal(t, "Home", resp.Props["title"])
+}
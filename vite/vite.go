@@ -2,11 +2,15 @@ package vite
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io/fs"
 	"net/http"
+	"path"
+	"slices"
 	"strings"
 
 	inertia "github.com/joetifa2003/inertigo"
@@ -217,6 +221,39 @@ func (v *Bundler) AssetPrefix() string {
 	return v.assetPrefix
 }
 
+// Pages lists the page components under dir, e.g. "assets/ts/pages", for
+// inertia.WithPages. In production they are read from the manifest, which
+// lists every page bundled through import.meta.glob; in dev they are read
+// from dir on disk, relative to the working directory. exts defaults to
+// inertia.DefaultPageExtensions.
+func (v *Bundler) Pages(dir string, exts ...string) inertia.PageSource {
+	if v.isDev {
+		return inertia.PagesFromDir(dir, exts...)
+	}
+
+	if len(exts) == 0 {
+		exts = inertia.DefaultPageExtensions
+	}
+	prefix := strings.TrimSuffix(path.Clean(dir), "/") + "/"
+
+	return func() ([]string, error) {
+		if v.manifest == nil {
+			return nil, errors.New("vite: no manifest loaded")
+		}
+
+		var pages []string
+		for _, chunk := range v.manifest {
+			name, ok := strings.CutPrefix(chunk.Src, prefix)
+			if !ok || !slices.Contains(exts, path.Ext(name)) {
+				continue
+			}
+			pages = append(pages, strings.TrimSuffix(name, path.Ext(name)))
+		}
+		slices.Sort(pages)
+		return pages, nil
+	}
+}
+
 // DevSSREngine implements inertia.BundlerDevSSR interface.
 // It returns an SSR engine that calls the Vite dev server's /render endpoint.
 func (v *Bundler) DevSSREngine() (inertia.SSREngine, error) {
@@ -227,18 +264,28 @@ type devSSREngine struct {
 	viteURL string
 }
 
-func (e *devSSREngine) Render(page inertia.PageObject) (inertia.RenderedPage, error) {
+func (e *devSSREngine) Render(ctx context.Context, page inertia.PageObject) (inertia.RenderedPage, error) {
 	pageJSON, err := json.Marshal(page)
 	if err != nil {
 		return inertia.RenderedPage{}, err
 	}
 
-	resp, err := http.Post(e.viteURL+"/render", "application/json", bytes.NewBuffer(pageJSON))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.viteURL+"/render", bytes.NewReader(pageJSON))
+	if err != nil {
+		return inertia.RenderedPage{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return inertia.RenderedPage{}, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return inertia.RenderedPage{}, fmt.Errorf("vite ssr render failed: %s", resp.Status)
+	}
+
 	var result inertia.RenderedPage
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return inertia.RenderedPage{}, err
@@ -1,14 +1,19 @@
 package vite
 
 import (
+	"context"
+	"encoding/json"
 	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	inertia "github.com/joetifa2003/inertigo"
 )
 
 // mockDistFS creates a mock filesystem with a manifest and some assets
@@ -176,3 +181,88 @@ func TestBundler_AssetPrefix(t *testing.T) {
 		assert.Equal(t, "/my-assets/", b.AssetPrefix())
 	})
 }
+
+func TestDevSSREngine_Render(t *testing.T) {
+	t.Run("posts the page object to the render endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/render", r.URL.Path)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			var page inertia.PageObject
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&page))
+
+			json.NewEncoder(w).Encode(inertia.RenderedPage{Body: "<div>" + page.Component + "</div>"})
+		}))
+		defer server.Close()
+
+		b, err := New(nil, WithDevMode(true), WithURL(server.URL))
+		require.NoError(t, err)
+
+		engine, err := b.DevSSREngine()
+		require.NoError(t, err)
+
+		page, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+		require.NoError(t, err)
+		assert.Equal(t, "<div>Home</div>", page.Body)
+	})
+
+	t.Run("returns an error on non-200 responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		engine := &devSSREngine{viteURL: server.URL}
+
+		_, err := engine.Render(context.Background(), inertia.PageObject{Component: "Home"})
+		assert.Error(t, err)
+	})
+
+	t.Run("honours context cancellation", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		engine := &devSSREngine{viteURL: server.URL}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := engine.Render(ctx, inertia.PageObject{Component: "Home"})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestBundler_Pages(t *testing.T) {
+	distFS := fstest.MapFS{
+		".vite/manifest.json": &fstest.MapFile{
+			Data: []byte(`{
+				"ts/app.tsx": {"file": "assets/app.js", "src": "ts/app.tsx", "isEntry": true},
+				"ts/pages/Home.tsx": {"file": "assets/Home.js", "src": "ts/pages/Home.tsx", "isDynamicEntry": true},
+				"ts/pages/users/Index.tsx": {"file": "assets/Index.js", "src": "ts/pages/users/Index.tsx", "isDynamicEntry": true},
+				"ts/pages/users/styles.css": {"file": "assets/styles.css", "src": "ts/pages/users/styles.css"},
+				"_shared.js": {"file": "assets/shared.js"}
+			}`),
+		},
+	}
+
+	b, err := New(distFS)
+	require.NoError(t, err)
+
+	pages, err := b.Pages("ts/pages/")()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Home", "users/Index"}, pages)
+
+	pages, err = b.Pages("ts/pages", ".jsx")()
+	require.NoError(t, err)
+	assert.Empty(t, pages)
+
+	b, err = New(nil)
+	require.NoError(t, err)
+
+	_, err = b.Pages("ts/pages")()
+	assert.Error(t, err)
+}